@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"luck/backend/store"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 管理接口鉴权 ===================== */
+
+// 管理令牌只从环境变量读取，不进 AppConfig（配置会落库并随备份导出）
+const adminTokenEnv = "LUCK_ADMIN_TOKEN"
+
+// requireAdmin：校验 Authorization: Bearer <token> 或 X-Admin-Token。
+// 未配置令牌时一律拒绝，避免写接口默认裸奔
+func requireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		want := os.Getenv(adminTokenEnv)
+		if want == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin api disabled: " + adminTokenEnv + " not set"})
+			return
+		}
+		got := c.GetHeader("X-Admin-Token")
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			got = strings.TrimSpace(bearer)
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// 审计里的操作人：X-Actor（令牌共用时用于区分）；缺省为 admin
+func adminActor(c *gin.Context) string {
+	if a := strings.TrimSpace(c.GetHeader("X-Actor")); a != "" {
+		return a
+	}
+	return "admin"
+}
+
+/* ===================== 备份 / 恢复 ===================== */
+
+// GET /api/admin/backup：下载一致性快照（VACUUM INTO），可直接作为 data/app.db 使用
+func backupHandler(c *gin.Context) {
+	name := fmt.Sprintf("app-%s.db", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/vnd.sqlite3")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	if err := st.Backup(c.Writer); err != nil {
+		// 尚未写出内容时还能返回 JSON 错误
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("backup: %v", err)
+	}
+}
+
+// POST /api/admin/restore（multipart 字段 file）：校验 schema 版本后整体替换数据
+func restoreHandler(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	rep, err := st.Restore(f)
+	switch {
+	case errors.Is(err, store.ErrSchemaTooNew):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, store.ErrInvalidBackup):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		log.Printf("restore by %s from %s: %+v", adminActor(c), c.ClientIP(), rep.Tables)
+		if err := loadAppConfig(); err != nil {
+			log.Printf("restore: reload config: %v", err)
+		}
+		sum, _ := st.HistorySummary()
+		c.JSON(http.StatusOK, gin.H{"ok": true, "report": rep, "summary": sum})
+	}
+}
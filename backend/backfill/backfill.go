@@ -0,0 +1,182 @@
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"luck/backend/provider"
+	"luck/backend/store"
+	"sort"
+	"strconv"
+	"time"
+)
+
+/* =============================== 缺期检测 =============================== */
+
+// 期号格式 YYYYNNN：每年从 001 连续编号
+type YearGaps struct {
+	Year    string   `json:"year"`
+	First   string   `json:"first"` // 库中该年最小期号
+	Last    string   `json:"last"`  // 库中该年最大期号
+	Have    int      `json:"have"`
+	Missing []string `json:"missing"` // 001..Last 之间缺失的期号
+}
+
+type GapReport struct {
+	Years     []YearGaps `json:"years"`
+	Missing   int        `json:"missing"`
+	Malformed []string   `json:"malformed,omitempty"` // 非 YYYYNNN 的期号（含空）
+}
+
+// Detect：按年查找缺期。只能发现每年最大期号之前的空洞；年末缺失的期无从得知。
+func Detect(draws []store.Draw) GapReport {
+	byYear := map[string]map[int]struct{}{}
+	var rep GapReport
+	for _, d := range draws {
+		year, n, ok := splitIssue(d.Issue)
+		if !ok {
+			rep.Malformed = append(rep.Malformed, d.Issue)
+			continue
+		}
+		if byYear[year] == nil {
+			byYear[year] = map[int]struct{}{}
+		}
+		byYear[year][n] = struct{}{}
+	}
+	years := make([]string, 0, len(byYear))
+	for y := range byYear {
+		years = append(years, y)
+	}
+	sort.Strings(years)
+
+	for _, y := range years {
+		set := byYear[y]
+		lo, hi := 1<<30, 0
+		for n := range set {
+			lo, hi = min(lo, n), max(hi, n)
+		}
+		yg := YearGaps{Year: y, First: issueOf(y, lo), Last: issueOf(y, hi), Have: len(set), Missing: []string{}}
+		for n := 1; n < hi; n++ {
+			if _, ok := set[n]; !ok {
+				yg.Missing = append(yg.Missing, issueOf(y, n))
+			}
+		}
+		rep.Missing += len(yg.Missing)
+		rep.Years = append(rep.Years, yg)
+	}
+	return rep
+}
+
+func splitIssue(issue string) (string, int, bool) {
+	if len(issue) != 7 {
+		return "", 0, false
+	}
+	if _, err := strconv.Atoi(issue[:4]); err != nil {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(issue[4:])
+	if err != nil || n < 1 {
+		return "", 0, false
+	}
+	return issue[:4], n, true
+}
+
+func issueOf(year string, n int) string { return fmt.Sprintf("%s%03d", year, n) }
+
+/* =============================== 回补 =============================== */
+
+type Options struct {
+	Year     string        // 只回补该年；空 = 全部
+	Limit    int           // 最多请求多少期；<=0 不限
+	Interval time.Duration // 相邻两次请求的间隔（第三方限流）
+}
+
+type Filled struct {
+	Issue  string `json:"issue"`
+	Status string `json:"status"` // inserted | updated | noop
+}
+
+type Missing struct {
+	Issue  string `json:"issue"`
+	Reason string `json:"reason"`
+}
+
+type Report struct {
+	Provider string     `json:"provider"`
+	Before   int        `json:"before"` // 回补前缺期数
+	Filled   []Filled   `json:"filled"`
+	Missing  []Missing  `json:"missing"` // 仍缺失（含超出 Limit 未请求的）
+	Gaps     *GapReport `json:"gaps"`    // 回补后重新检测
+}
+
+// Run：检测缺期并逐期按期号查询、经 store.ValidateDraw 校验后 ReconcileIssue
+func Run(ctx context.Context, st *store.Store, p provider.IssueProvider, opt Options) (*Report, error) {
+	draws, err := st.ListRecentDraws(0)
+	if err != nil {
+		return nil, err
+	}
+	gaps := Detect(draws)
+	rep := &Report{Provider: p.Name(), Before: gaps.Missing, Filled: []Filled{}, Missing: []Missing{}}
+
+	requested := 0
+	for _, yg := range gaps.Years {
+		if opt.Year != "" && yg.Year != opt.Year {
+			continue
+		}
+		for _, issue := range yg.Missing {
+			if opt.Limit > 0 && requested >= opt.Limit {
+				rep.Missing = append(rep.Missing, Missing{Issue: issue, Reason: "limit reached"})
+				continue
+			}
+			if ctx.Err() != nil {
+				rep.Missing = append(rep.Missing, Missing{Issue: issue, Reason: ctx.Err().Error()})
+				continue
+			}
+			if requested > 0 && opt.Interval > 0 {
+				// 请求间隔内客户端断开/超时立即停止，不再等满
+				select {
+				case <-ctx.Done():
+					rep.Missing = append(rep.Missing, Missing{Issue: issue, Reason: ctx.Err().Error()})
+					continue
+				case <-time.After(opt.Interval):
+				}
+			}
+			requested++
+			status, reason := fill(ctx, st, p, issue)
+			if reason != "" {
+				rep.Missing = append(rep.Missing, Missing{Issue: issue, Reason: reason})
+				continue
+			}
+			rep.Filled = append(rep.Filled, Filled{Issue: issue, Status: status})
+		}
+	}
+
+	if draws, err = st.ListRecentDraws(0); err != nil {
+		return nil, err
+	}
+	after := Detect(draws)
+	rep.Gaps = &after
+	return rep, nil
+}
+
+// 返回入库状态；失败时返回原因
+func fill(ctx context.Context, st *store.Store, p provider.IssueProvider, issue string) (string, string) {
+	d, err := p.ByIssue(ctx, issue)
+	if err != nil {
+		return "", err.Error()
+	}
+	if d == nil {
+		return "", "not found at provider"
+	}
+	if d.Issue != issue {
+		return "", fmt.Sprintf("provider returned issue %s", d.Issue)
+	}
+	// 与同步最新一期相同的校验：第三方数据有误时不入库
+	if err := store.ValidateDraw(*d); err != nil {
+		return "", "invalid draw: " + err.Error()
+	}
+	status, _, err := st.ReconcileIssue(*d)
+	if err != nil {
+		return "", "reconcile: " + err.Error()
+	}
+	return status, ""
+}
@@ -0,0 +1,115 @@
+package backfill
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"luck/backend/store"
+)
+
+func draw(issue string) store.Draw {
+	return store.Draw{Issue: issue, DrawDate: "2024-01-02", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9,
+		Source: "test", FetchedAt: time.Now()}
+}
+
+// 按期号返回固定号码；每次请求后调用 onCall，edit 非 nil 时改写返回值
+type fakeProvider struct {
+	onCall func()
+	edit   func(*store.Draw)
+}
+
+func (p fakeProvider) Name() string { return "fake" }
+
+func (p fakeProvider) Latest(ctx context.Context) (*store.Draw, error) { return nil, nil }
+
+func (p fakeProvider) ByIssue(ctx context.Context, issue string) (*store.Draw, error) {
+	if p.onCall != nil {
+		p.onCall()
+	}
+	d := draw(issue)
+	if p.edit != nil {
+		p.edit(&d)
+	}
+	return &d, nil
+}
+
+func openStore(t *testing.T, issues ...string) *store.Store {
+	t.Helper()
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	for _, issue := range issues {
+		if _, _, err := st.ReconcileIssue(draw(issue)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return st
+}
+
+func TestRunFillsGaps(t *testing.T) {
+	st := openStore(t, "2024001", "2024004")
+	rep, err := Run(context.Background(), st, fakeProvider{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Before != 2 || len(rep.Filled) != 2 || len(rep.Missing) != 0 || rep.Gaps.Missing != 0 {
+		t.Fatalf("report = %+v", rep)
+	}
+}
+
+// 请求间隔内取消：立即返回，未请求的期记为缺失
+func TestRunStopsWaitingOnCancel(t *testing.T) {
+	st := openStore(t, "2024001", "2024005")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	rep, err := Run(ctx, st, fakeProvider{onCall: cancel}, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Run waited %v after cancel", elapsed)
+	}
+	if len(rep.Filled) != 1 || len(rep.Missing) != 2 {
+		t.Fatalf("filled %v, missing %v", rep.Filled, rep.Missing)
+	}
+	for _, m := range rep.Missing {
+		if m.Reason != context.Canceled.Error() {
+			t.Errorf("missing %s reason = %q", m.Issue, m.Reason)
+		}
+	}
+}
+
+// 第三方返回的号码不合法（重复红球、蓝球越界）：不入库，记为缺失
+func TestRunRejectsInvalidDraws(t *testing.T) {
+	st := openStore(t, "2024001", "2024005")
+	bad := map[string]func(*store.Draw){
+		"2024002": func(d *store.Draw) { d.Reds = []int{1, 5, 5, 18, 25, 31} },
+		"2024003": func(d *store.Draw) { d.Blue = 17 },
+	}
+	p := fakeProvider{edit: func(d *store.Draw) {
+		if f := bad[d.Issue]; f != nil {
+			f(d)
+		}
+	}}
+	rep, err := Run(context.Background(), st, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Filled) != 1 || rep.Filled[0].Issue != "2024004" || len(rep.Missing) != 2 {
+		t.Fatalf("filled %v, missing %v", rep.Filled, rep.Missing)
+	}
+	for _, m := range rep.Missing {
+		if !strings.HasPrefix(m.Reason, "invalid draw: ") {
+			t.Errorf("missing %s reason = %q", m.Issue, m.Reason)
+		}
+		if d, err := st.GetByIssue(m.Issue); err != nil || d != nil {
+			t.Errorf("invalid draw %s stored: %+v, %v", m.Issue, d, err)
+		}
+	}
+}
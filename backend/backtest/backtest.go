@@ -0,0 +1,183 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"luck/backend/generator"
+	"luck/backend/prize"
+	"luck/backend/store"
+)
+
+/* =============================== 参数 & 报告 =============================== */
+
+var ErrNoIssues = errors.New("no issues in range")
+
+type Options struct {
+	Config  generator.Config
+	From    string // 起始期号（含）；空 = 不限
+	To      string // 结束期号（含）；空 = 不限
+	Tickets int    // 每期票数；>0 时覆盖 Config.GenerateCount（并忽略预算）
+	Limit   int    // 最多回测多少期（取区间内最近的 N 期）；<=0 不限
+
+	BaselineRuns int // 随机基线模拟组数；<=0 不做对比
+}
+
+// 命中分布键："红+蓝"，如 "4+1"；按每张票的最佳一注统计
+type IssueReport struct {
+	Issue      string             `json:"issue"`
+	DrawDate   string             `json:"draw_date"`
+	Draw       store.Draw         `json:"draw"`
+	Seed       int64              `json:"seed"`
+	Tickets    []generator.Ticket `json:"tickets,omitempty"`
+	Bets       int                `json:"bets"`
+	CostYuan   int                `json:"cost_yuan"`
+	PrizeYuan  int                `json:"prize_yuan"` // 仅固定奖金
+	Floating   int                `json:"floating"`   // 一/二等奖注数（浮动奖金未计入）
+	BestTier   prize.Tier         `json:"best_tier"`
+	Hits       map[string]int     `json:"hits"`
+	TierCounts map[prize.Tier]int `json:"tier_counts"`
+}
+
+type Summary struct {
+	Issues     int                `json:"issues"`
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	Bets       int                `json:"bets"`
+	CostYuan   int                `json:"cost_yuan"`
+	PrizeYuan  int                `json:"prize_yuan"`
+	Floating   int                `json:"floating"`
+	ROI        float64            `json:"roi"`         // (奖金-成本)/成本，浮动奖金不计
+	WinIssues  int                `json:"win_issues"`  // 有任意中奖的期数
+	Hits       map[string]int     `json:"hits"`        // 按票最佳一注
+	TierCounts map[prize.Tier]int `json:"tier_counts"` // 按注
+}
+
+type Report struct {
+	Summary  Summary         `json:"summary"`
+	PerIssue []IssueReport   `json:"per_issue"`
+	Baseline *BaselineReport `json:"baseline,omitempty"`
+}
+
+/* =============================== 回放 =============================== */
+
+// Run：draws 须为时间升序（同 Store.ListRecentDraws）；对区间内每一期，
+// 只用其之前的开奖作为历史生成号码，再与该期开奖对奖。
+// Config.Seed 非 0 时第 i 期使用 Seed+i，整份报告可复现。
+func Run(draws []store.Draw, opt Options) (*Report, error) {
+	cfg := opt.Config
+	if opt.Tickets > 0 {
+		cfg.GenerateCount = opt.Tickets
+		cfg.BudgetYuan = 0
+	}
+
+	idx := issueRange(draws, opt.From, opt.To)
+	if opt.Limit > 0 && len(idx) > opt.Limit {
+		idx = idx[len(idx)-opt.Limit:]
+	}
+	if len(idx) == 0 {
+		return nil, ErrNoIssues
+	}
+
+	rep := &Report{
+		Summary: Summary{
+			Hits:       map[string]int{},
+			TierCounts: map[prize.Tier]int{},
+		},
+		PerIssue: make([]IssueReport, 0, len(idx)),
+	}
+	for n, i := range idx {
+		use := cfg
+		if cfg.Seed != 0 {
+			use.Seed = cfg.Seed + int64(n)
+		}
+		tickets, seed, err := generator.LuckTicketsFrom(use, draws[:i])
+		if err != nil {
+			return nil, fmt.Errorf("issue %s: %w", draws[i].Issue, err)
+		}
+		ir := scoreIssue(draws[i], tickets)
+		ir.Seed = seed
+		rep.PerIssue = append(rep.PerIssue, ir)
+		rep.Summary.add(ir)
+	}
+	rep.Summary.From = rep.PerIssue[0].Issue
+	rep.Summary.To = rep.PerIssue[len(rep.PerIssue)-1].Issue
+	if rep.Summary.CostYuan > 0 {
+		rep.Summary.ROI = float64(rep.Summary.PrizeYuan-rep.Summary.CostYuan) / float64(rep.Summary.CostYuan)
+	}
+	if opt.BaselineRuns > 0 {
+		b, err := Baseline(rep, opt.BaselineRuns, cfg.Seed)
+		if err != nil {
+			return nil, err
+		}
+		rep.Baseline = b
+	}
+	return rep, nil
+}
+
+func scoreIssue(d store.Draw, tickets []generator.Ticket) IssueReport {
+	ir := IssueReport{
+		Issue:      d.Issue,
+		DrawDate:   d.DrawDate,
+		Draw:       d,
+		Tickets:    tickets,
+		Hits:       map[string]int{},
+		TierCounts: map[prize.Tier]int{},
+	}
+	for _, t := range tickets {
+		r := prize.CheckTicket(t, d)
+		ir.Bets += r.Bets
+		ir.PrizeYuan += r.Amount
+		ir.Floating += r.Floating
+		ir.Hits[HitKey(r.Best.RedHits, r.Best.BlueHit)]++
+		for tier, n := range r.TierCounts {
+			ir.TierCounts[tier] += n
+			if ir.BestTier == prize.None || tier < ir.BestTier {
+				ir.BestTier = tier
+			}
+		}
+	}
+	ir.CostYuan = ir.Bets * prize.PricePerBet
+	return ir
+}
+
+func (s *Summary) add(ir IssueReport) {
+	s.Issues++
+	s.Bets += ir.Bets
+	s.CostYuan += ir.CostYuan
+	s.PrizeYuan += ir.PrizeYuan
+	s.Floating += ir.Floating
+	if ir.BestTier != prize.None {
+		s.WinIssues++
+	}
+	for k, n := range ir.Hits {
+		s.Hits[k] += n
+	}
+	for t, n := range ir.TierCounts {
+		s.TierCounts[t] += n
+	}
+}
+
+/* =============================== 小工具 =============================== */
+
+func HitKey(redHits int, blueHit bool) string {
+	if blueHit {
+		return fmt.Sprintf("%d+1", redHits)
+	}
+	return fmt.Sprintf("%d+0", redHits)
+}
+
+// 区间内各期在 draws 中的下标；第 0 期没有历史，不参与回测
+func issueRange(draws []store.Draw, from, to string) []int {
+	var out []int
+	for i := 1; i < len(draws); i++ {
+		is := draws[i].Issue
+		if from != "" && is < from {
+			continue
+		}
+		if to != "" && is > to {
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"luck/backend/generator"
+	"luck/backend/prize"
+	"luck/backend/store"
+)
+
+// 固定种子的开奖序列（时间升序，期号 2024001 起）
+func fixtureDraws(n int) []store.Draw {
+	r := rand.New(rand.NewSource(5))
+	out := make([]store.Draw, 0, n)
+	for i := 0; i < n; i++ {
+		reds := r.Perm(33)[:6]
+		for j := range reds {
+			reds[j]++
+		}
+		sort.Ints(reds)
+		out = append(out, store.Draw{
+			Issue:    fmt.Sprintf("2024%03d", i+1),
+			DrawDate: fmt.Sprintf("2024-%02d-%02d", 1+i/28, 1+i%28),
+			Reds:     reds,
+			Blue:     1 + r.Intn(16),
+		})
+	}
+	return out
+}
+
+func fixtureConfig() generator.Config {
+	cfg := generator.DefaultConfig()
+	cfg.Seed = 20240101
+	cfg.GenerateCount = 3
+	return cfg
+}
+
+func TestRunSlicesChronologically(t *testing.T) {
+	draws := fixtureDraws(40)
+	cfg := fixtureConfig()
+	rep, err := Run(draws, Options{Config: cfg, From: "2024031", To: "2024036"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Summary.Issues != 6 || rep.Summary.From != "2024031" || rep.Summary.To != "2024036" {
+		t.Fatalf("summary range = %d %s..%s", rep.Summary.Issues, rep.Summary.From, rep.Summary.To)
+	}
+	for n, ir := range rep.PerIssue {
+		i := 30 + n // draws 下标
+		if ir.Issue != draws[i].Issue || !reflect.DeepEqual(ir.Draw, draws[i]) {
+			t.Fatalf("per_issue[%d] = %s, want %s", n, ir.Issue, draws[i].Issue)
+		}
+		// 第 n 期只用之前的开奖、种子为 Seed+n
+		use := cfg
+		use.Seed = cfg.Seed + int64(n)
+		want, _, err := generator.LuckTicketsFrom(use, draws[:i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ir.Seed != use.Seed || !reflect.DeepEqual(ir.Tickets, want) {
+			t.Fatalf("issue %s: tickets/seed differ from generating on draws[:%d] with seed %d", ir.Issue, i, use.Seed)
+		}
+	}
+
+	// limit 取区间内最近 N 期；第 0 期没有历史，不参与
+	rep, err = Run(draws, Options{Config: cfg, Limit: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Summary.Issues != 4 || rep.Summary.From != "2024037" || rep.Summary.To != "2024040" {
+		t.Fatalf("limit: %d %s..%s", rep.Summary.Issues, rep.Summary.From, rep.Summary.To)
+	}
+	if got := issueRange(draws, "", "2024003"); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("issueRange = %v, want [1 2]", got)
+	}
+
+	if _, err := Run(draws, Options{Config: cfg, From: "2025001"}); !errors.Is(err, ErrNoIssues) {
+		t.Fatalf("empty range: err = %v", err)
+	}
+}
+
+func TestRunReproducible(t *testing.T) {
+	draws := fixtureDraws(30)
+	opt := Options{Config: fixtureConfig(), Limit: 5, BaselineRuns: 20}
+	a, err := Run(draws, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Run(draws, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same options gave different reports")
+	}
+}
+
+func TestScoreIssueAndROI(t *testing.T) {
+	d := store.Draw{Issue: "2024098", Reds: []int{3, 9, 14, 20, 27, 31}, Blue: 12}
+	tickets := []generator.Ticket{
+		{Type: generator.TicketSingle, Reds: []int{3, 9, 14, 20, 27, 33}, Blues: []int{12}}, // 5+1 三等奖 3000
+		{Type: generator.TicketSingle, Reds: []int{1, 2, 4, 5, 6, 7}, Blues: []int{12}},     // 0+1 六等奖 5
+		{Type: generator.TicketSingle, Reds: []int{1, 2, 4, 5, 6, 7}, Blues: []int{1}},      // 未中
+		{Type: generator.TicketMultiple, Reds: []int{3, 9, 14, 20, 27, 31, 1}, Blues: []int{2}},
+	}
+	ir := scoreIssue(d, tickets)
+	// 复式 7 红：6+0 一注（二等奖，浮动）+ 5+0 六注（四等奖 200）
+	if ir.Bets != 10 || ir.CostYuan != 10*prize.PricePerBet {
+		t.Fatalf("bets/cost = %d/%d", ir.Bets, ir.CostYuan)
+	}
+	if ir.PrizeYuan != 3000+5+6*200 || ir.Floating != 1 || ir.BestTier != prize.Second {
+		t.Fatalf("prize = %d floating = %d best = %v", ir.PrizeYuan, ir.Floating, ir.BestTier)
+	}
+	wantHits := map[string]int{"5+1": 1, "0+1": 1, "0+0": 1, "6+0": 1}
+	if !reflect.DeepEqual(ir.Hits, wantHits) {
+		t.Fatalf("hits = %v, want %v", ir.Hits, wantHits)
+	}
+	if ir.TierCounts[prize.Second] != 1 || ir.TierCounts[prize.Fourth] != 6 || ir.TierCounts[prize.Third] != 1 || ir.TierCounts[prize.Sixth] != 1 {
+		t.Fatalf("tier counts = %v", ir.TierCounts)
+	}
+
+	draws := fixtureDraws(20)
+	rep, err := Run(draws, Options{Config: fixtureConfig(), Limit: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cost, won int
+	for _, ir := range rep.PerIssue {
+		cost += ir.CostYuan
+		won += ir.PrizeYuan
+	}
+	s := rep.Summary
+	if s.CostYuan != cost || s.PrizeYuan != won || s.Bets*prize.PricePerBet != cost {
+		t.Fatalf("summary cost/prize = %d/%d, per-issue sums %d/%d", s.CostYuan, s.PrizeYuan, cost, won)
+	}
+	if want := float64(won-cost) / float64(cost); s.ROI != want {
+		t.Fatalf("roi = %v, want %v", s.ROI, want)
+	}
+}
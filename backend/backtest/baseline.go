@@ -0,0 +1,278 @@
+package backtest
+
+import (
+	"fmt"
+	"luck/backend/generator"
+	"luck/backend/prize"
+	"luck/backend/store"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+/* =============================== 随机基线（Monte Carlo） =============================== */
+
+// 单次请求最多模拟的随机票组数
+const MaxBaselineRuns = 1000
+
+// 单次请求最多模拟的随机票张数（组数 × 期数 × 每期票数）；超出时按比例减少组数。
+// 回测接口是同步的，这个上限决定了基线最坏情况下的耗时
+const MaxBaselineTickets = 500_000
+
+// 至少模拟的组数：期数×票数很大时也保留这么多组，否则分位/区间没有意义
+const minBaselineRuns = 20
+
+// 与策略逐项对比的指标：
+//   - prize_yuan：固定奖金合计
+//   - red_hits / blue_hits：每张票红/蓝命中个数合计（胆码+拖码/全部红球与开奖的交集）
+var baselineMetrics = []string{"prize_yuan", "red_hits", "blue_hits"}
+
+// Diff/DiffCI95 是“策略 − 随机”期望差的 95% 置信区间：以每期的配对差
+// （策略值 − 该期随机均值）为样本，Diff ± 1.96·SE，SE 同时计入策略自身的逐期波动
+// 与随机均值的模拟误差。区间不含 0 才算可区分。
+type MetricCompare struct {
+	Strategy        float64    `json:"strategy"`
+	Mean            float64    `json:"mean"` // 随机基线均值
+	Std             float64    `json:"std"`
+	Band95          [2]float64 `json:"band95"`     // 随机基线自身 2.5%~97.5% 分位（仅供参考）
+	Percentile      float64    `json:"percentile"` // 策略值在随机基线中的分位（0~100）
+	Z               float64    `json:"z"`
+	Diff            float64    `json:"diff"`            // 策略 − 随机均值
+	DiffCI95        [2]float64 `json:"diff_ci95"`       // Diff 的 95% 置信区间
+	Distinguishable bool       `json:"distinguishable"` // DiffCI95 不含 0
+}
+
+type BaselineReport struct {
+	Runs            int                      `json:"runs"`      // 实际模拟组数
+	Requested       int                      `json:"requested"` // 请求的组数（可能因上限被减少）
+	Seed            int64                    `json:"seed"`
+	Metrics         map[string]MetricCompare `json:"metrics"`
+	Distinguishable bool                     `json:"distinguishable"` // 任一指标可区分
+	Verdict         string                   `json:"verdict"`
+}
+
+// Baseline：对报告中的每一期，生成 runs 组与策略票型完全相同（票数、红/胆/蓝个数）的
+// 均匀随机票，与同一期开奖对奖，再与策略结果逐指标比较。rep.PerIssue 须带 Tickets。
+func Baseline(rep *Report, runs int, seed int64) (*BaselineReport, error) {
+	if runs <= 0 {
+		return nil, fmt.Errorf("baseline runs must be > 0")
+	}
+	requested := runs
+	runs = baselineRuns(rep, runs)
+	if seed == 0 {
+		seed = 1
+	}
+
+	// perIssue[k][i]：第 i 期策略值；randMean[k][i]：第 i 期随机值的均值
+	perIssue := make(map[string][]float64, len(baselineMetrics))
+	randMean := make(map[string][]float64, len(baselineMetrics))
+	for _, k := range baselineMetrics {
+		perIssue[k] = make([]float64, len(rep.PerIssue))
+		randMean[k] = make([]float64, len(rep.PerIssue))
+	}
+	for i, ir := range rep.PerIssue {
+		for k, v := range measure(ir.Draw, ir.Tickets) {
+			perIssue[k][i] = v
+		}
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	samples := make(map[string][]float64, len(baselineMetrics))
+	for n := 0; n < runs; n++ {
+		total := map[string]float64{}
+		for i, ir := range rep.PerIssue {
+			rnd := make([]generator.Ticket, len(ir.Tickets))
+			for j, t := range ir.Tickets {
+				rnd[j] = randomLike(r, t)
+			}
+			for k, v := range measure(ir.Draw, rnd) {
+				total[k] += v
+				randMean[k][i] += v / float64(runs)
+			}
+		}
+		for _, k := range baselineMetrics {
+			samples[k] = append(samples[k], total[k])
+		}
+	}
+
+	out := &BaselineReport{Runs: runs, Requested: requested, Seed: seed, Metrics: make(map[string]MetricCompare, len(baselineMetrics))}
+	for _, k := range baselineMetrics {
+		mc := compare(perIssue[k], randMean[k], samples[k])
+		out.Metrics[k] = mc
+		if mc.Distinguishable {
+			out.Distinguishable = true
+		}
+	}
+	out.Verdict = verdict(out)
+	return out, nil
+}
+
+// 按 MaxBaselineRuns / MaxBaselineTickets 收紧组数
+func baselineRuns(rep *Report, runs int) int {
+	runs = min(runs, MaxBaselineRuns)
+	tickets := 0
+	for _, ir := range rep.PerIssue {
+		tickets += len(ir.Tickets)
+	}
+	if tickets > 0 && runs*tickets > MaxBaselineTickets {
+		runs = max(MaxBaselineTickets/tickets, min(runs, minBaselineRuns))
+	}
+	return runs
+}
+
+func measure(d store.Draw, tickets []generator.Ticket) map[string]float64 {
+	m := make(map[string]float64, len(baselineMetrics))
+	for _, t := range tickets {
+		r := prize.CheckTicket(t, d)
+		m["prize_yuan"] += float64(r.Amount)
+		m["red_hits"] += float64(countIn(t.Bankers, d.Reds) + countIn(t.Reds, d.Reds))
+		m["blue_hits"] += float64(countIn(t.Blues, []int{d.Blue}))
+	}
+	return m
+}
+
+// 与 t 同票型、同号码个数的均匀随机票
+func randomLike(r *rand.Rand, t generator.Ticket) generator.Ticket {
+	reds := r.Perm(33)[:len(t.Bankers)+len(t.Reds)]
+	blues := r.Perm(16)[:len(t.Blues)]
+	out := generator.Ticket{Type: t.Type}
+	for i, v := range reds {
+		if i < len(t.Bankers) {
+			out.Bankers = append(out.Bankers, v+1)
+		} else {
+			out.Reds = append(out.Reds, v+1)
+		}
+	}
+	for _, v := range blues {
+		out.Blues = append(out.Blues, v+1)
+	}
+	sort.Ints(out.Bankers)
+	sort.Ints(out.Reds)
+	sort.Ints(out.Blues)
+	return out
+}
+
+// strategy/randMean 为逐期值，samples 为每组随机票的全区间合计
+func compare(strategy, randMean, samples []float64) MetricCompare {
+	s := append([]float64(nil), samples...)
+	sort.Float64s(s)
+	n := float64(len(s))
+
+	v := 0.0
+	for _, x := range strategy {
+		v += x
+	}
+	mean, std := meanStd(s)
+
+	// 中位秩：相等的样本各算一半
+	below, equal := 0, 0
+	for _, x := range s {
+		switch {
+		case x < v:
+			below++
+		case x == v:
+			equal++
+		}
+	}
+	mc := MetricCompare{
+		Strategy:   v,
+		Mean:       mean,
+		Std:        std,
+		Band95:     [2]float64{quantile(s, 0.025), quantile(s, 0.975)},
+		Percentile: 100 * (float64(below) + float64(equal)/2) / n,
+		Diff:       v - mean,
+	}
+	if std > 0 {
+		mc.Z = (v - mean) / std
+	}
+
+	// 策略合计的方差取两者较大值：逐期配对差的方差 × 期数，与随机合计的方差（零假设下同分布）。
+	// 奖金是长尾分布，策略没中过大奖时逐期方差会严重偏小，只用它会把运气当成差异。
+	// 再加上随机均值本身的模拟误差 std²/runs
+	varStrategy := std * std
+	if len(strategy) > 1 {
+		d := make([]float64, len(strategy))
+		for i := range strategy {
+			d[i] = strategy[i] - randMean[i]
+		}
+		_, sd := meanStd(d)
+		varStrategy = max(varStrategy, float64(len(d))*sd*sd)
+	}
+	se := math.Sqrt(varStrategy + std*std/n)
+	mc.DiffCI95 = [2]float64{mc.Diff - 1.96*se, mc.Diff + 1.96*se}
+	mc.Distinguishable = mc.DiffCI95[0] > 0 || mc.DiffCI95[1] < 0
+	return mc
+}
+
+// 样本均值与（无偏）标准差
+func meanStd(s []float64) (mean, std float64) {
+	if len(s) == 0 {
+		return 0, 0
+	}
+	n := float64(len(s))
+	for _, x := range s {
+		mean += x
+	}
+	mean /= n
+	if len(s) < 2 {
+		return mean, 0
+	}
+	vari := 0.0
+	for _, x := range s {
+		vari += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(vari / (n - 1))
+}
+
+// 线性插值分位数；s 已升序
+func quantile(s []float64, q float64) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	pos := q * float64(len(s)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return s[lo] + (s[hi]-s[lo])*(pos-float64(lo))
+}
+
+func verdict(b *BaselineReport) string {
+	if !b.Distinguishable {
+		return "与均匀随机无显著差异（所有指标“策略 − 随机”的 95% 置信区间均包含 0）"
+	}
+	var better, worse []string
+	for _, k := range baselineMetrics {
+		mc := b.Metrics[k]
+		if !mc.Distinguishable {
+			continue
+		}
+		if mc.Diff > 0 {
+			better = append(better, k)
+		} else {
+			worse = append(worse, k)
+		}
+	}
+	s := "与均匀随机可区分："
+	if len(better) > 0 {
+		s += fmt.Sprintf("优于随机 %v", better)
+	}
+	if len(worse) > 0 {
+		if len(better) > 0 {
+			s += "；"
+		}
+		s += fmt.Sprintf("劣于随机 %v", worse)
+	}
+	return s
+}
+
+func countIn(a, set []int) int {
+	n := 0
+	for _, v := range a {
+		for _, w := range set {
+			if v == w {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package backtest
+
+import (
+	"math/rand"
+	"testing"
+
+	"luck/backend/generator"
+	"luck/backend/store"
+)
+
+// 每期 tickets 张单式票；pick 决定票面号码
+func fakeReport(issues, tickets int, pick func(r *rand.Rand, d store.Draw) generator.Ticket) *Report {
+	r := rand.New(rand.NewSource(11))
+	rep := &Report{}
+	for i := 0; i < issues; i++ {
+		d := store.Draw{Reds: randomLike(r, generator.Ticket{Reds: make([]int, 6)}).Reds, Blue: 1 + r.Intn(16)}
+		ir := IssueReport{Draw: d}
+		for j := 0; j < tickets; j++ {
+			ir.Tickets = append(ir.Tickets, pick(r, d))
+		}
+		rep.PerIssue = append(rep.PerIssue, ir)
+	}
+	return rep
+}
+
+func randomSingle(r *rand.Rand, _ store.Draw) generator.Ticket {
+	return randomLike(r, generator.Ticket{Type: generator.TicketSingle, Reds: make([]int, 6), Blues: make([]int, 1)})
+}
+
+func TestBaselineRandomStrategyNotDistinguishable(t *testing.T) {
+	rep := fakeReport(100, 5, randomSingle)
+	b, err := Baseline(rep, 200, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, mc := range b.Metrics {
+		if mc.DiffCI95[0] > mc.Diff || mc.DiffCI95[1] < mc.Diff {
+			t.Errorf("%s: diff %v outside its own CI %v", k, mc.Diff, mc.DiffCI95)
+		}
+		if mc.Distinguishable {
+			t.Errorf("%s: random strategy reported distinguishable: %+v", k, mc)
+		}
+	}
+}
+
+func TestBaselineCheatingStrategyDistinguishable(t *testing.T) {
+	// 每张票都押中 3 个开奖红球和蓝球
+	rep := fakeReport(50, 5, func(r *rand.Rand, d store.Draw) generator.Ticket {
+		tk := randomSingle(r, d)
+		copy(tk.Reds, d.Reds[:3])
+		tk.Blues[0] = d.Blue
+		return tk
+	})
+	b, err := Baseline(rep, 200, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Distinguishable {
+		t.Fatalf("cheating strategy not distinguishable: %+v", b)
+	}
+	for _, k := range []string{"red_hits", "blue_hits"} {
+		if mc := b.Metrics[k]; !mc.Distinguishable || mc.DiffCI95[0] <= 0 {
+			t.Errorf("%s: want CI above 0, got %+v", k, mc)
+		}
+	}
+}
+
+func TestBaselineRunsBounded(t *testing.T) {
+	rep := fakeReport(500, 20, randomSingle)
+	if got := baselineRuns(rep, MaxBaselineRuns*10); got*500*20 > MaxBaselineTickets {
+		t.Fatalf("runs = %d exceeds ticket budget", got)
+	}
+	small := fakeReport(10, 1, randomSingle)
+	if got := baselineRuns(small, MaxBaselineRuns*10); got != MaxBaselineRuns {
+		t.Fatalf("runs = %d, want %d", got, MaxBaselineRuns)
+	}
+	huge := fakeReport(500, 100, randomSingle)
+	if got := baselineRuns(huge, 200); got != minBaselineRuns {
+		t.Fatalf("runs = %d, want floor %d", got, minBaselineRuns)
+	}
+}
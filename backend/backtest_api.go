@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"luck/backend/backtest"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 回测 ===================== */
+
+// 单次请求最多回放的期数（每期都要重新生成号码）
+const maxBacktestIssues = 500
+
+// 默认随机基线模拟组数
+const defaultBaselineRuns = 200
+
+// config 与 /api/generate 相同：覆盖到服务端当前配置（或 preset）上，可只给部分字段
+type backtestRequest struct {
+	Preset      string          `json:"preset"`
+	Config      json.RawMessage `json:"config"`
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Tickets     int             `json:"tickets"`      // 每期票数；0 = 按 config
+	Limit       int             `json:"limit"`        // 最多回测期数；0 = 区间全部（上限 maxBacktestIssues）
+	WithTickets bool            `json:"with_tickets"` // 是否返回每期生成的号码
+	Baseline    int             `json:"baseline"`     // 随机基线模拟组数；0 = 默认，<0 = 不做对比
+}
+
+func backtestHandler(c *gin.Context) {
+	var in backtestRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	use := appConfig().Config.Clone()
+	if in.Preset != "" {
+		var ok bool
+		if use, _, ok = loadPreset(c, in.Preset); !ok {
+			return
+		}
+	}
+	if len(in.Config) > 0 {
+		if err := json.Unmarshal(in.Config, &use); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad config: " + err.Error()})
+			return
+		}
+	}
+	if in.Limit <= 0 || in.Limit > maxBacktestIssues {
+		in.Limit = maxBacktestIssues
+	}
+	if in.Baseline == 0 {
+		in.Baseline = defaultBaselineRuns
+	}
+	draws, err := st.ListRecentDraws(0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	rep, err := backtest.Run(draws, backtest.Options{
+		Config:  use,
+		From:    in.From,
+		To:      in.To,
+		Tickets: in.Tickets,
+		Limit:   in.Limit,
+
+		BaselineRuns: in.Baseline,
+	})
+	if errors.Is(err, backtest.ErrNoIssues) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(generateErrorStatus(err), generateErrorBody(err))
+		return
+	}
+	if !in.WithTickets {
+		for i := range rep.PerIssue {
+			rep.PerIssue[i].Tickets = nil
+		}
+	}
+	c.JSON(http.StatusOK, rep)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 服务端配置（持久化） ===================== */
+
+// settings 表中保存 AppConfig 的 key
+const appConfigKey = "app_config"
+
+// 启动时用库里保存的配置覆盖内置默认值；新增字段缺省时保留默认。
+// 保存的生成配置校验不通过时整体忽略
+func loadAppConfig() error {
+	raw, err := st.GetSetting(appConfigKey)
+	if err != nil || raw == nil {
+		return err
+	}
+	cfgWriteMu.Lock()
+	defer cfgWriteMu.Unlock()
+	next := appConfig().clone()
+	if err := json.Unmarshal(raw, &next); err != nil {
+		return err
+	}
+	if err := next.Config.Validate(); err != nil {
+		return err
+	}
+	cfgMu.Lock()
+	cfg = next
+	cfgMu.Unlock()
+	return nil
+}
+
+// appConfig：当前配置的快照。配置只整体替换，快照里的切片/map 之后不会再被改动，可在锁外放心读取；
+// 一次请求/一次拉取内只取一次，避免前后读到两版配置
+func appConfig() AppConfig {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return cfg
+}
+
+// 深拷贝：在副本上 json.Unmarshal 不会改动当前配置的切片/map
+func (c AppConfig) clone() AppConfig {
+	c.AllowOrigins = slices.Clone(c.AllowOrigins)
+	c.Config = c.Config.Clone()
+	c.APIProviders = slices.Clone(c.APIProviders)
+	c.APIKeys = maps.Clone(c.APIKeys)
+	c.APIEndpoints = maps.Clone(c.APIEndpoints)
+	return c
+}
+
+/* ----------------------------- 凭据打码 ----------------------------- */
+
+// 打码后的凭据：只露末 4 位便于核对；过短的整体隐藏
+func maskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// 对外展示的配置：api_key / api_keys 打码
+func (c AppConfig) redacted() AppConfig {
+	c.APIKey = maskSecret(c.APIKey)
+	if len(c.APIKeys) > 0 {
+		keys := make(map[string]string, len(c.APIKeys))
+		for name, v := range c.APIKeys {
+			keys[name] = maskSecret(v)
+		}
+		c.APIKeys = keys
+	}
+	return c
+}
+
+// GET 取回后原样 PUT 时，打码值表示“不修改”，恢复为 prev 中的原值
+func (c *AppConfig) keepMaskedSecrets(prev AppConfig) {
+	if prev.APIKey != "" && c.APIKey == maskSecret(prev.APIKey) {
+		c.APIKey = prev.APIKey
+	}
+	for name, v := range c.APIKeys {
+		if old := prev.APIKeys[name]; old != "" && v == maskSecret(old) {
+			c.APIKeys[name] = old
+		}
+	}
+}
+
+// GET /api/config：凭据打码返回
+func getConfigHandler(c *gin.Context) { c.JSON(http.StatusOK, appConfig().redacted()) }
+
+// PUT /api/config（需管理令牌）：请求体覆盖到当前配置上（可只给部分字段），校验通过后落库；
+// 自动拉取随之启停，port / allow_origins 重启后生效。api_endpoint 可指向本地文件（file 源），因此不对匿名开放
+func putConfigHandler(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 并发 PUT 排队，不会互相覆盖；落库时只持 cfgWriteMu，appConfig() 的读者不等磁盘
+	cfgWriteMu.Lock()
+	defer cfgWriteMu.Unlock()
+	prev := appConfig()
+	next := prev.clone()
+	if err := json.Unmarshal(body, &next); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	next.keepMaskedSecrets(prev)
+	if err := next.Config.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, generateErrorBody(err))
+		return
+	}
+	if err := st.PutSetting(appConfigKey, next); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "save config failed: " + err.Error()})
+		return
+	}
+	cfgMu.Lock()
+	cfg = next
+	cfgMu.Unlock()
+	applySchedulerConfig(next)
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestConfigSecretsRoundTrip(t *testing.T) {
+	prev := AppConfig{APIKey: "appid123:secret9876", APIKeys: map[string]string{"jisu": "abcd1234efgh", "x": "short"}}
+	shown := prev.clone().redacted()
+	if shown.APIKey != "****9876" || shown.APIKeys["jisu"] != "****efgh" || shown.APIKeys["x"] != "****" {
+		t.Fatalf("redacted = %q %v", shown.APIKey, shown.APIKeys)
+	}
+	if prev.APIKeys["jisu"] != "abcd1234efgh" {
+		t.Fatal("redacted modified the original map")
+	}
+
+	// 原样 PUT 回打码值：保持原凭据
+	next := shown.clone()
+	next.keepMaskedSecrets(prev)
+	if next.APIKey != prev.APIKey || next.APIKeys["jisu"] != prev.APIKeys["jisu"] || next.APIKeys["x"] != "short" {
+		t.Fatalf("masked values not restored: %q %v", next.APIKey, next.APIKeys)
+	}
+
+	// 给出新值 / 清空：照常生效
+	next = shown.clone()
+	next.APIKey, next.APIKeys["jisu"] = "", "new-key-0000"
+	next.keepMaskedSecrets(prev)
+	if next.APIKey != "" || next.APIKeys["jisu"] != "new-key-0000" {
+		t.Fatalf("explicit values overwritten: %q %v", next.APIKey, next.APIKeys)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"luck/backend/backfill"
+	"luck/backend/provider"
+	"luck/backend/scheduler"
+	"luck/backend/store"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 最新一期：拉取 + 交叉校验 + 入库 ===================== */
+
+var (
+	errDrawNotFound = errors.New("latest draw not found")
+	errBadProvider  = errors.New("provider config")
+	errInvalidDraw  = errors.New("invalid draw")
+)
+
+type syncResult struct {
+	Draw   *Draw
+	Status string // inserted | updated | noop
+	Prev   *Draw
+	Quorum *provider.QuorumResult // 仅多源模式
+}
+
+// syncLatestDraw：按配置拉取最新一期并 ReconcileIssue。
+// 多源模式下未达成法定数不入库，分歧写入 draw_conflicts。
+func syncLatestDraw(ctx context.Context) (*syncResult, error) {
+	conf := appConfig() // 每次拉取取一次快照：期间配置被 PUT/恢复替换也不受影响
+	res := &syncResult{}
+	var ext *Draw
+	if len(conf.APIProviders) > 0 {
+		ps, err := configuredProviders(conf)
+		if err != nil {
+			return nil, err
+		}
+		q, err := provider.Agree(ctx, ps, conf.APIQuorum)
+		res.Quorum = q
+		if q != nil {
+			if rerr := recordConflicts(q); rerr != nil {
+				return res, fmt.Errorf("record conflicts: %w", rerr)
+			}
+		}
+		if err != nil {
+			return res, err
+		}
+		ext = q.Draw
+	} else {
+		p, err := latestDrawProvider(conf)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadProvider, err)
+		}
+		if ext, err = p.Latest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if ext == nil {
+		return res, errDrawNotFound
+	}
+	if err := store.ValidateDraw(*ext); err != nil {
+		return res, fmt.Errorf("%w: %v", errInvalidDraw, err)
+	}
+	if ext.FetchedAt.IsZero() {
+		ext.FetchedAt = time.Now()
+	}
+
+	status, prev, err := st.ReconcileIssue(*ext)
+	if err != nil {
+		return res, fmt.Errorf("persist failed: %w", err)
+	}
+	res.Draw, res.Status, res.Prev = ext, status, prev
+	return res, nil
+}
+
+func syncErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errDrawNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errBadProvider), errors.Is(err, errInvalidDraw):
+		return http.StatusInternalServerError
+	default: // 第三方请求失败 / 未达成法定数
+		return http.StatusBadGateway
+	}
+}
+
+// 按配置构造最新一期的来源（APIProvider：mxnzp / jisu / file）
+func latestDrawProvider(conf AppConfig) (provider.DrawProvider, error) {
+	return providerByName(conf, conf.APIProvider)
+}
+
+func configuredProviders(conf AppConfig) ([]provider.DrawProvider, error) {
+	out := make([]provider.DrawProvider, 0, len(conf.APIProviders))
+	for _, name := range conf.APIProviders {
+		p, err := providerByName(conf, name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadProvider, err)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func providerByName(conf AppConfig, name string) (provider.DrawProvider, error) {
+	opt := provider.Options{}
+	if name == conf.APIProvider {
+		opt.APIKey, opt.Endpoint = conf.APIKey, conf.APIEndpoint
+	}
+	if v, ok := conf.APIKeys[name]; ok {
+		opt.APIKey = v
+	}
+	if v, ok := conf.APIEndpoints[name]; ok {
+		opt.Endpoint = v
+	}
+	return provider.New(name, opt)
+}
+
+func recordConflicts(q *provider.QuorumResult) error {
+	list := make([]store.DrawConflict, 0, len(q.Conflicts))
+	for _, c := range q.Conflicts {
+		list = append(list, store.DrawConflict{
+			Issue:    c.Issue,
+			Provider: c.Provider,
+			DrawDate: c.Draw.DrawDate,
+			Reds:     c.Draw.Reds,
+			Blue:     c.Draw.Blue,
+			Reason:   c.Reason,
+			Accepted: q.Draw,
+		})
+	}
+	return st.RecordConflicts(list)
+}
+
+// GET /api/draw/conflicts?issue=&limit=
+func listDrawConflictsHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	list, err := st.ListConflicts(c.Query("issue"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+/* ===================== 开奖日自动拉取 ===================== */
+
+var sched *scheduler.Scheduler
+
+func newDrawScheduler() *scheduler.Scheduler {
+	return scheduler.New(func(ctx context.Context) (*store.Draw, error) {
+		res, err := syncLatestDraw(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return res.Draw, nil
+	}, scheduler.DefaultOptions(), func() bool {
+		conf := appConfig()
+		return conf.UseAPISource && conf.SchedulerEnabled
+	})
+}
+
+// 未开启第三方源（默认）或关闭自动拉取时不调度；配置变更后随之启停
+func applySchedulerConfig(conf AppConfig) {
+	if conf.UseAPISource && conf.SchedulerEnabled {
+		sched.Start()
+	} else {
+		sched.Stop()
+	}
+}
+
+// GET /api/scheduler：上次/下次运行时间、最近错误
+func schedulerStatusHandler(c *gin.Context) {
+	conf := appConfig()
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": conf.UseAPISource && conf.SchedulerEnabled,
+		"status":  sched.Status(),
+	})
+}
+
+/* ===================== 缺期检测 & 回补 ===================== */
+
+// 单次回补最多请求的期数
+const maxBackfillIssues = 200
+
+// GET /api/history/gaps
+func historyGapsHandler(c *gin.Context) {
+	draws, err := st.ListRecentDraws(0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, backfill.Detect(draws))
+}
+
+type backfillRequest struct {
+	Provider string `json:"provider"` // 空 = APIProvider
+	Year     string `json:"year"`
+	Limit    int    `json:"limit"`
+}
+
+// POST /api/history/backfill：按期号逐期回补缺期
+func historyBackfillHandler(c *gin.Context) {
+	var in backfillRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	conf := appConfig()
+	if in.Provider == "" {
+		in.Provider = conf.APIProvider
+	}
+	if in.Limit <= 0 || in.Limit > maxBackfillIssues {
+		in.Limit = maxBackfillIssues
+	}
+	p, err := providerByName(conf, in.Provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ip, ok := p.(provider.IssueProvider)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("provider %s does not support by-issue queries", p.Name())})
+		return
+	}
+	rep, err := backfill.Run(c.Request.Context(), st, ip, backfill.Options{
+		Year:     in.Year,
+		Limit:    in.Limit,
+		Interval: 200 * time.Millisecond,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
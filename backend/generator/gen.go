@@ -1,10 +1,11 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"luck/backend/store"
 	"math/rand"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,6 +22,13 @@ const (
 
 /* =============================== 默认配置 & 校验 =============================== */
 
+// 生成失败的分类；具体原因以 %w 包装，调用方用 errors.Is 区分
+var (
+	ErrInvalidConfig      = errors.New("invalid config")         // 参数本身不合法
+	ErrInfeasible         = errors.New("constraints infeasible") // 参数合法但约束过严，无法凑出号码
+	ErrHistoryUnavailable = errors.New("history unavailable")    // 读取历史开奖失败
+)
+
 func DefaultConfig() Config {
 	return Config{
 		Mode:            ModeMixed,
@@ -58,16 +66,21 @@ func DefaultConfig() Config {
 	}
 }
 
-func enforceBudget(cfg *Config) {
+func enforceBudget(cfg *Config) error {
 	if cfg.BudgetYuan > 0 {
-		maxByBudget := cfg.BudgetYuan / pricePerTicketYuan
+		// 复式/胆拖每张票含多注，按整张票的花费换算
+		maxByBudget := cfg.BudgetYuan / (pricePerTicketYuan * max(1, betsPerTicket(*cfg)))
 		if maxByBudget < cfg.GenerateCount {
 			cfg.GenerateCount = maxByBudget
 		}
 	}
 	if cfg.GenerateCount <= 0 {
-		log.Fatalf("生成注数必须 > 0")
+		if cfg.BudgetYuan > 0 {
+			return fmt.Errorf("%w: 预算 %d 元不足一张票", ErrInvalidConfig, cfg.BudgetYuan)
+		}
+		return fmt.Errorf("%w: 生成注数必须 > 0", ErrInvalidConfig)
 	}
+	return nil
 }
 
 /* =============================== 基础枚举与配置 =============================== */
@@ -149,6 +162,26 @@ type Config struct {
 	Bands          BandRange
 	BandTemplates  [][3]int // 每项为 {Low, Mid, High}，三者和必须为 6
 	TemplateRepeat int      // 每个模板连续使用多少注（默认 2）
+
+	// 随机种子：0 表示自动生成；相同 Config + Seed + 历史数据 → 相同结果
+	Seed int64
+
+	// 投注方式（GenerateCount 为票数；复式/胆拖每张票含多注）
+	TicketType  TicketType
+	MultiRed    int // 复式红球个数（6~20）
+	MultiBlue   int // 复式/胆拖蓝球个数（<=1 视为 1）
+	BankerCount int // 胆拖：胆码个数（1~5）
+	DragCount   int // 胆拖：拖码个数（胆+拖 >= 7）
+}
+
+// Clone：深拷贝切片字段；在副本上 json.Unmarshal 局部覆盖时不会改动原配置
+func (c Config) Clone() Config {
+	c.RedFilter = slices.Clone(c.RedFilter)
+	c.BlueFilter = slices.Clone(c.BlueFilter)
+	c.FixedRed = slices.Clone(c.FixedRed)
+	c.StartBuckets = slices.Clone(c.StartBuckets)
+	c.BandTemplates = slices.Clone(c.BandTemplates)
+	return c
 }
 
 /* =============================== 生成器封装 =============================== */
@@ -169,28 +202,91 @@ type Generator struct {
 
 /* =============================== main =============================== */
 
-func LuckCombo(cfg Config) ([]Combo, error) {
-	enforceBudget(&cfg)
-
-	g := mustNewGenerator(cfg)
+// LuckCombo：返回生成结果与实际使用的种子（可回填 Config.Seed 复现）
+func LuckCombo(cfg Config, hist HistorySource) ([]Combo, int64, error) {
+	g, err := newPlannedGenerator(cfg, hist)
+	if err != nil {
+		return nil, 0, err
+	}
+	combos, err := g.generateAndWriteAll()
+	return combos, g.cfg.Seed, err
+}
 
-	g.planBlueSequence()
-	g.planAnchorSequence()
-	g.prepareLuckyList()
+// LuckTickets：按 TicketType 生成单式/复式/胆拖票；每张票以一注经约束生成的单式为底，再扩展红/蓝
+func LuckTickets(cfg Config, hist HistorySource) ([]Ticket, int64, error) {
+	g, err := newPlannedGenerator(cfg, hist)
+	if err != nil {
+		return nil, 0, err
+	}
+	return g.generateTickets()
+}
 
-	return g.generateAndWriteAll()
+// LuckTicketsFrom：以给定开奖列表作为历史生成，供回测使用
+func LuckTicketsFrom(cfg Config, draws []store.Draw) ([]Ticket, int64, error) {
+	return LuckTickets(cfg, DrawHistory(draws))
+}
 
+func (g *Generator) generateTickets() ([]Ticket, int64, error) {
+	combos, err := g.generateAndWriteAll()
+	if err != nil {
+		return nil, g.cfg.Seed, err
+	}
+	out := make([]Ticket, 0, len(combos))
+	for _, c := range combos {
+		t := g.expandTicket(c)
+		if err := t.Validate(); err != nil {
+			return nil, g.cfg.Seed, fmt.Errorf("%w: 票型参数无效: %w", ErrInvalidConfig, err)
+		}
+		out = append(out, t)
+	}
+	return out, g.cfg.Seed, nil
 }
 
 /* =============================== Generator 构造 & 规划 =============================== */
 
-func mustNewGenerator(cfg Config) *Generator {
-	//f, err := excelize.OpenFile(excelPath)
-	//if err != nil {
-	//	log.Fatalf("打开 Excel 失败: %v", err)
-	//}
-	//redHistory, histFreq, nextRow := readHistoryAndNextRowWithFreq(f)
+func newPlannedGenerator(cfg Config, hist HistorySource) (*Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	cfg, err := prepareConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if hist == nil {
+		return nil, fmt.Errorf("%w: no history source", ErrHistoryUnavailable)
+	}
+	redHistory, histFreq, err := hist.HistorySetAndFreq()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrHistoryUnavailable, err)
+	}
+	g := newGenerator(cfg, redHistory, histFreq)
+	if err := g.plan(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// 预算折算 + 种子落定
+func prepareConfig(cfg Config) (Config, error) {
+	if err := enforceBudget(&cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = newSeed()
+	}
+	return cfg, nil
+}
+
+func (g *Generator) plan() error {
+	if err := g.planBlueSequence(); err != nil {
+		return err
+	}
+	g.planAnchorSequence()
+	g.prepareLuckyList()
+	return nil
+}
 
+func newGenerator(cfg Config, redHistory map[string]struct{}, histFreq [34]int) *Generator {
 	// 计算单号 cap
 	capPer := 1 << 30
 	if cfg.UsePerNumberCap {
@@ -200,22 +296,9 @@ func mustNewGenerator(cfg Config) *Generator {
 			capPer = 2
 		}
 	}
-	st, err := store.Open("data")
-	if err != nil {
-		panic(err)
-	}
-	defer func() {
-		if err := st.Close(); err != nil {
-			panic(err)
-		}
-	}()
-	redHistory, histFreq, err := st.HistorySetAndFreq()
-	if err != nil {
-		panic(err)
-	}
 	return &Generator{
 		cfg: cfg,
-		r:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		r:   rand.New(rand.NewSource(cfg.Seed)),
 		//nextRow:      nextRow,
 		redHistory:   redHistory,
 		histFreq:     histFreq,
@@ -223,10 +306,10 @@ func mustNewGenerator(cfg Config) *Generator {
 	}
 }
 
-func (g *Generator) planBlueSequence() {
+func (g *Generator) planBlueSequence() error {
 	avail := buildAvailableBlues(g.cfg.BlueFilter)
 	if len(avail) == 0 {
-		log.Fatalf("蓝球可用列表为空（过滤过严？）")
+		return fmt.Errorf("%w: 蓝球可用列表为空（过滤过严？）", ErrInfeasible)
 	}
 	n := g.cfg.GenerateCount
 	L := len(avail)
@@ -242,14 +325,15 @@ func (g *Generator) planBlueSequence() {
 		}
 		offset := seed % L
 		g.blueSeq = roundRobin(base, n, offset)
-		return
+		return nil
 	}
 	if g.cfg.Animal >= Rat && g.cfg.Animal <= Pig {
 		offset := int(g.cfg.Animal-1) % L
 		g.blueSeq = roundRobin(base, n, offset)
-		return
+		return nil
 	}
 	g.blueSeq = roundRobin(base, n, 0)
+	return nil
 }
 
 func (g *Generator) planAnchorSequence() {
@@ -294,7 +378,7 @@ func (g *Generator) generateAndWriteAll() ([]Combo, error) {
 		if !ok {
 			red, ok = bruteForceUniqueIgnoringConstraints(&g.histFreq, &g.currFreq, g.redHistory, g.r, g.cfg.RedFilter, minStart)
 			if !ok {
-				return nil, fmt.Errorf("兜底也失败：请降低约束/减少过滤/减少注数")
+				return nil, fmt.Errorf("%w: 兜底也失败：请降低约束/减少过滤/减少注数", ErrInfeasible)
 			}
 		}
 
@@ -326,7 +410,6 @@ func (g *Generator) generateAndWriteAll() ([]Combo, error) {
 			Reds: red,
 			Blue: blue,
 		})
-		fmt.Printf(" %v,  %02d\n", red, blue)
 	}
 	return cbx, nil
 }
@@ -368,7 +451,7 @@ func (g *Generator) generateLuckBall() ([]string, error) {
 		if !ok {
 			red, ok = bruteForceUniqueIgnoringConstraints(&g.histFreq, &g.currFreq, g.redHistory, g.r, g.cfg.RedFilter, minStart)
 			if !ok {
-				return nil, fmt.Errorf("兜底也失败：请降低约束/减少过滤/减少注数")
+				return nil, fmt.Errorf("%w: 兜底也失败：请降低约束/减少过滤/减少注数", ErrInfeasible)
 			}
 		}
 
@@ -416,20 +499,101 @@ func (g *Generator) generateLuckBall() ([]string, error) {
 //	return nil
 //}
 
+/* =============================== 复式 / 胆拖扩展 =============================== */
+
+func (g *Generator) expandTicket(c Combo) Ticket {
+	blues := g.extraBlues(c.Blue, max(1, g.cfg.MultiBlue))
+	nr, nb := ticketReds(g.cfg)
+	switch g.cfg.TicketType {
+	case TicketMultiple:
+		reds := g.extraReds(c.Reds, nr)
+		sort.Ints(reds)
+		return Ticket{Type: TicketMultiple, Reds: reds, Blues: blues}
+	case TicketBanker:
+		// 胆码：幸运号优先，其余按原顺序补足；剩余红球并入拖码
+		bankers := make([]int, 0, nb)
+		for _, n := range c.Reds {
+			if len(bankers) < nb && contains(g.luckyAll, n) {
+				bankers = append(bankers, n)
+			}
+		}
+		for _, n := range c.Reds {
+			if len(bankers) < nb && !contains(bankers, n) {
+				bankers = append(bankers, n)
+			}
+		}
+		drags := make([]int, 0, nr)
+		for _, n := range c.Reds {
+			if !contains(bankers, n) {
+				drags = append(drags, n)
+			}
+		}
+		drags = g.extraReds(drags, nr, bankers...)
+		sort.Ints(bankers)
+		sort.Ints(drags)
+		return Ticket{Type: TicketBanker, Reds: drags, Bankers: bankers, Blues: blues}
+	default:
+		return Ticket{Type: TicketSingle, Reds: c.Reds, Blues: blues[:1]}
+	}
+}
+
+// extraReds：补足到 total 个红球；按（历史+本次）频次冷号优先，过滤号与 exclude 不选
+func (g *Generator) extraReds(base []int, total int, exclude ...int) []int {
+	out := append([]int(nil), base...)
+	block := toSet(g.cfg.RedFilter)
+	for len(out) < total {
+		pick := -1
+		for n := 1; n <= 33; n++ {
+			if _, bad := block[n]; bad || contains(out, n) || contains(exclude, n) {
+				continue
+			}
+			if pick == -1 || g.histFreq[n]+g.currFreq[n] < g.histFreq[pick]+g.currFreq[pick] {
+				pick = n
+			}
+		}
+		if pick == -1 {
+			break // 可选号码不足，交由 Validate 报错
+		}
+		out = append(out, pick)
+		g.currFreq[pick]++
+	}
+	return out
+}
+
+// extraBlues：从本注蓝球起，沿可用蓝球列表顺延取 n 个
+func (g *Generator) extraBlues(first, n int) []int {
+	avail := buildAvailableBlues(g.cfg.BlueFilter)
+	start := 0
+	for i, b := range avail {
+		if b == first {
+			start = i
+			break
+		}
+	}
+	out := []int{first}
+	for i := 1; i < len(avail) && len(out) < n; i++ {
+		if b := avail[(start+i)%len(avail)]; b != first {
+			out = append(out, b)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
 /* =============================== 规划：锚点区间 =============================== */
 
 func buildStartAnchorPlan(n int, buckets []StartBucket, cfg Config) []int {
 	if n <= 0 {
 		return nil
 	}
-	// 稳定随机：生日 > 生肖 > 当前时间
+	// 稳定随机：生日 > 生肖 > Config.Seed
 	var seed int64
 	if y, m, d, ok := parseBirthday(cfg.Birthday); ok {
 		seed = int64(stableSeedFromBirthday(y, m, d))
 	} else if cfg.Animal >= Rat && cfg.Animal <= Pig {
 		seed = int64(20011 + int(cfg.Animal)*137)
 	} else {
-		seed = time.Now().UnixNano()
+		seed = cfg.Seed
 	}
 	r := rand.New(rand.NewSource(seed))
 
@@ -520,6 +684,10 @@ func buildStartAnchorPlan(n int, buckets []StartBucket, cfg Config) []int {
 		}
 	}
 	for len(final) < n {
+		// 28 个锚点都已用满（注数 > 28×MaxPerAnchor）：放宽上限，否则死循环
+		if len(final) >= 28*maxPer {
+			maxPer++
+		}
 		a := 1 + r.Intn(28)
 		if used[a] < maxPer {
 			final = append(final, a)
@@ -886,10 +1054,10 @@ func respectOverlap(trial []int, existing [][6]int, maxOverlap int) bool {
 	return true
 }
 
-func readHistoryAndNextRowWithFreq(f *excelize.File) (map[string]struct{}, [34]int, int) {
+func readHistoryAndNextRowWithFreq(f *excelize.File) (map[string]struct{}, [34]int, int, error) {
 	rows, err := f.GetRows(sheetName)
 	if err != nil {
-		log.Fatalf("读取 Excel 行失败: %v", err)
+		return nil, [34]int{}, 0, fmt.Errorf("%w: 读取 Excel 行失败: %w", ErrHistoryUnavailable, err)
 	}
 	history := make(map[string]struct{}, 4096)
 	var freq [34]int
@@ -925,7 +1093,7 @@ func readHistoryAndNextRowWithFreq(f *excelize.File) (map[string]struct{}, [34]i
 	if !foundEmpty && len(rows) > 0 {
 		next = len(rows) + 1
 	}
-	return history, freq, next
+	return history, freq, next, nil
 }
 
 /* =============================== Lucky / Blue / Utils =============================== */
@@ -978,6 +1146,15 @@ func roundRobin(base []int, n, offset int) []int {
 	return res
 }
 
+// 非 0 的随机种子
+func newSeed() int64 {
+	s := time.Now().UnixNano() & (1<<53 - 1) // 控制在 2^53 内，JSON/前端数字不丢精度
+	if s == 0 {
+		s = 1
+	}
+	return s
+}
+
 func stableSeedFromBirthday(y, m, d int) int {
 	base := y*10000 + m*100 + d
 	return base*131 + digitSum(y+m+d)*17
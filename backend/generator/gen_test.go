@@ -0,0 +1,224 @@
+package generator
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"luck/backend/store"
+)
+
+// 固定种子造一段历史：确定性，且足够让冷热统计/去重起作用
+func fakeHistory(n int) DrawHistory {
+	r := rand.New(rand.NewSource(7))
+	h := make(DrawHistory, 0, n)
+	for i := 0; i < n; i++ {
+		reds := r.Perm(33)[:6]
+		for j := range reds {
+			reds[j]++
+		}
+		sort.Ints(reds)
+		h = append(h, store.Draw{Reds: reds, Blue: r.Intn(16) + 1})
+	}
+	return h
+}
+
+type failingHistory struct{}
+
+func (failingHistory) HistorySetAndFreq() (map[string]struct{}, [34]int, error) {
+	return nil, [34]int{}, errors.New("disk on fire")
+}
+
+func TestLuckTicketsDeterministic(t *testing.T) {
+	hist := fakeHistory(200)
+	cases := []struct {
+		name string
+		edit func(*Config)
+	}{
+		{"single", func(c *Config) {}},
+		{"multiple", func(c *Config) { c.TicketType, c.MultiRed, c.MultiBlue = TicketMultiple, 8, 2 }},
+		{"banker", func(c *Config) { c.TicketType, c.BankerCount, c.DragCount = TicketBanker, 2, 6 }},
+		{"random mode", func(c *Config) { c.Mode, c.Birthday, c.Animal = ModeRandom, "", 0 }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Seed = 20240825
+			tc.edit(&cfg)
+
+			first, seed1, err := LuckTickets(cfg, hist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, seed2, err := LuckTickets(cfg.Clone(), hist)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seed1 != cfg.Seed || seed2 != cfg.Seed {
+				t.Fatalf("seed = %d/%d, want %d", seed1, seed2, cfg.Seed)
+			}
+			if !reflect.DeepEqual(first, second) {
+				t.Fatalf("same config + seed gave different tickets:\n%v\n%v", first, second)
+			}
+			if len(first) != cfg.GenerateCount {
+				t.Fatalf("got %d tickets, want %d", len(first), cfg.GenerateCount)
+			}
+			for i, tk := range first {
+				if tk.Type != cfg.TicketType {
+					t.Errorf("ticket %d type = %v, want %v", i, tk.Type, cfg.TicketType)
+				}
+				if err := tk.Validate(); err != nil {
+					t.Errorf("ticket %d invalid: %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+// Seed 为 0 时自动取种子；把返回的种子回填即可复现
+func TestLuckTicketsReplayAutoSeed(t *testing.T) {
+	hist := fakeHistory(100)
+	cfg := DefaultConfig()
+	first, seed, err := LuckTickets(cfg, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed == 0 {
+		t.Fatal("auto seed not reported")
+	}
+	cfg.Seed = seed
+	again, _, err := LuckTickets(cfg, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, again) {
+		t.Fatal("replaying the reported seed gave different tickets")
+	}
+}
+
+// 生成的单式不与历史开奖重复，同一批内也不重复
+func TestLuckComboAvoidsHistory(t *testing.T) {
+	hist := fakeHistory(300)
+	set, _, _ := hist.HistorySetAndFreq()
+	cfg := DefaultConfig()
+	cfg.Seed = 99
+	cfg.GenerateCount = 30
+	combos, _, err := LuckCombo(cfg, hist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, c := range combos {
+		key := redKeyStr(c.Reds)
+		if _, dup := set[key]; dup {
+			t.Errorf("%v repeats a historical draw", c.Reds)
+		}
+		if seen[key] {
+			t.Errorf("%v generated twice", c.Reds)
+		}
+		seen[key] = true
+	}
+}
+
+func TestLuckTicketsErrors(t *testing.T) {
+	hist := fakeHistory(50)
+	cases := []struct {
+		name string
+		edit func(*Config)
+		hist HistorySource
+		want error
+	}{
+		{"invalid count", func(c *Config) { c.GenerateCount = 0 }, hist, ErrInvalidConfig},
+		{"invalid bands", func(c *Config) { c.Bands.MidLo = 10 }, hist, ErrInvalidConfig},
+		{"budget below one ticket", func(c *Config) {
+			c.TicketType, c.MultiRed, c.BudgetYuan = TicketMultiple, 8, 10
+		}, hist, ErrInvalidConfig},
+		{"nil history", func(c *Config) {}, nil, ErrHistoryUnavailable},
+		{"history error", func(c *Config) {}, failingHistory{}, ErrHistoryUnavailable},
+		{"only six reds left", func(c *Config) {
+			// 只剩 1..6 可选：第一注之后再无不重复的组合
+			c.RedFilter = nil
+			for n := 7; n <= 33; n++ {
+				c.RedFilter = append(c.RedFilter, n)
+			}
+			c.Mode, c.Birthday, c.Animal = ModeRandom, "", 0
+			c.FixedRed, c.FixedPerTicket = nil, 0
+			c.StartBuckets = nil
+			c.GenerateCount = 2
+		}, hist, ErrInfeasible},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Seed = 1
+			tc.edit(&cfg)
+			_, _, err := LuckTickets(cfg, tc.hist)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateFieldErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.GenerateCount = 0
+	cfg.RedFilter = []int{3, 3, 40}
+	cfg.TicketType = TicketBanker
+	cfg.BankerCount, cfg.DragCount = 6, 0
+
+	err := cfg.Validate()
+	var ve ValidationErrors
+	if !errors.As(err, &ve) || !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("err = %v, want ValidationErrors wrapping ErrInvalidConfig", err)
+	}
+	got := map[string]bool{}
+	for _, fe := range ve {
+		got[fe.Field] = true
+	}
+	for _, f := range []string{"GenerateCount", "RedFilter[1]", "RedFilter[2]", "BankerCount", "DragCount"} {
+		if !got[f] {
+			t.Errorf("missing field error %s in %v", f, ve)
+		}
+	}
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+}
+
+// 6 红 1 蓝的复式等同单式，须在 Validate 阶段按字段报出
+func TestValidateMultipleNeedsMoreThanSingle(t *testing.T) {
+	cases := []struct {
+		red, blue int
+		bad       bool
+	}{
+		{6, 0, true},
+		{6, 1, true},
+		{6, 2, false},
+		{7, 1, false},
+	}
+	for _, tc := range cases {
+		cfg := DefaultConfig()
+		cfg.TicketType, cfg.MultiRed, cfg.MultiBlue = TicketMultiple, tc.red, tc.blue
+		err := cfg.Validate()
+		if !tc.bad {
+			if err != nil {
+				t.Errorf("%d+%d: unexpected %v", tc.red, tc.blue, err)
+			}
+			continue
+		}
+		var ve ValidationErrors
+		if !errors.As(err, &ve) {
+			t.Fatalf("%d+%d: err = %v, want ValidationErrors", tc.red, tc.blue, err)
+		}
+		got := map[string]bool{}
+		for _, fe := range ve {
+			got[fe.Field] = true
+		}
+		if !got["MultiRed"] || !got["MultiBlue"] {
+			t.Errorf("%d+%d: fields %v, want MultiRed and MultiBlue", tc.red, tc.blue, ve)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"luck/backend/store"
+	"sort"
+)
+
+/* =============================== 历史数据来源 =============================== */
+
+// HistorySource：生成器去重与冷热统计所需的历史（红球组合集合 + 1..33 频次）。
+// 服务端直接传入 *store.Store；回测/离线场景用 DrawHistory
+type HistorySource interface {
+	HistorySetAndFreq() (map[string]struct{}, [34]int, error)
+}
+
+var _ HistorySource = (*store.Store)(nil)
+
+// DrawHistory：内存中的开奖列表，口径与 store.HistorySetAndFreq 一致
+type DrawHistory []store.Draw
+
+func (h DrawHistory) HistorySetAndFreq() (map[string]struct{}, [34]int, error) {
+	set := make(map[string]struct{}, len(h))
+	var freq [34]int
+	for _, d := range h {
+		if len(d.Reds) != 6 {
+			continue
+		}
+		reds := append([]int(nil), d.Reds...)
+		sort.Ints(reds)
+		set[redKeyStr(reds)] = struct{}{}
+		for _, v := range reds {
+			if v >= 1 && v <= 33 {
+				freq[v]++
+			}
+		}
+	}
+	return set, freq, nil
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/* =============================== 枚举的 JSON 名称 =============================== */
+
+// 输出名称；输入接受名称（不区分大小写）或数字，兼容旧请求与已保存的配置。
+// 超出范围的数字原样输出，交给 Validate 报错
+var (
+	modeNames      = []string{"random", "zodiac", "birthday", "mixed"}
+	fixedModeNames = []string{"always", "rotate"}
+	zodiacNames    = []string{"", "Rat", "Ox", "Tiger", "Rabbit", "Dragon", "Snake", "Horse", "Goat", "Monkey", "Rooster", "Dog", "Pig"} // 0 = 不用生肖
+)
+
+func (m Mode) MarshalJSON() ([]byte, error) { return marshalEnum(modeNames, int(m)) }
+func (m *Mode) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, modeNames, "mode", (*int)(m))
+}
+
+func (f FixedMode) MarshalJSON() ([]byte, error) { return marshalEnum(fixedModeNames, int(f)) }
+func (f *FixedMode) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, fixedModeNames, "fixed mode", (*int)(f))
+}
+
+func (z ChineseZodiac) MarshalJSON() ([]byte, error) { return marshalEnum(zodiacNames, int(z)) }
+func (z *ChineseZodiac) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, zodiacNames, "animal", (*int)(z))
+}
+
+func (t TicketType) MarshalJSON() ([]byte, error) { return marshalEnum(ticketTypeNames[:], int(t)) }
+func (t *TicketType) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, ticketTypeNames[:], "ticket type", (*int)(t))
+}
+
+func marshalEnum(names []string, v int) ([]byte, error) {
+	if v < 0 || v >= len(names) {
+		return json.Marshal(v)
+	}
+	return json.Marshal(names[v])
+}
+
+func unmarshalEnum(b []byte, names []string, what string, out *int) error {
+	var n int
+	if err := json.Unmarshal(b, &n); err == nil {
+		*out = n
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%s must be a name or number: %s", what, b)
+	}
+	for i, name := range names {
+		if strings.EqualFold(name, strings.TrimSpace(s)) {
+			*out = i
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s %q (want one of %s)", what, s, strings.Join(names, ", "))
+}
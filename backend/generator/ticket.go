@@ -0,0 +1,245 @@
+package generator
+
+import (
+	"fmt"
+	"luck/backend/store"
+	"sort"
+)
+
+/* =============================== 投注方式 =============================== */
+
+type TicketType int
+
+const (
+	TicketSingle   TicketType = iota // 单式：6 红 + 1 蓝
+	TicketMultiple                   // 复式：7~20 红 和/或 2+ 蓝
+	TicketBanker                     // 胆拖：胆码 + 拖码
+)
+
+var ticketTypeNames = [...]string{"single", "multiple", "banker"}
+
+func (t TicketType) String() string {
+	if t < TicketSingle || t > TicketBanker {
+		return "unknown"
+	}
+	return ticketTypeNames[t]
+}
+
+func parseTicketType(s string) (TicketType, bool) {
+	if s == "" {
+		return TicketSingle, true
+	}
+	for i, n := range ticketTypeNames {
+		if n == s {
+			return TicketType(i), true
+		}
+	}
+	return 0, false
+}
+
+// 一张票：可展开为若干注 6+1
+type Ticket struct {
+	Type    TicketType `json:"type"`
+	Reds    []int      `json:"reds"`              // 单式/复式：全部红球；胆拖：拖码
+	Bankers []int      `json:"bankers,omitempty"` // 胆拖：胆码
+	Blues   []int      `json:"blues"`
+}
+
+func SingleTicket(c Combo) Ticket {
+	return Ticket{Type: TicketSingle, Reds: append([]int(nil), c.Reds...), Blues: []int{c.Blue}}
+}
+
+/* =============================== 规则 & 注数 =============================== */
+
+const (
+	maxMultiRed  = 20
+	maxBankerRed = 5
+)
+
+func (t Ticket) Validate() error {
+	if err := checkNumbers(t.Reds, 1, 33, "red"); err != nil {
+		return err
+	}
+	if err := checkNumbers(t.Bankers, 1, 33, "banker"); err != nil {
+		return err
+	}
+	if err := checkNumbers(t.Blues, 1, 16, "blue"); err != nil {
+		return err
+	}
+	if len(t.Blues) == 0 {
+		return fmt.Errorf("blues required")
+	}
+	switch t.Type {
+	case TicketSingle:
+		if len(t.Reds) != 6 || len(t.Blues) != 1 || len(t.Bankers) != 0 {
+			return fmt.Errorf("single ticket must be 6 reds + 1 blue")
+		}
+	case TicketMultiple:
+		if len(t.Bankers) != 0 {
+			return fmt.Errorf("multiple ticket must not have bankers")
+		}
+		if len(t.Reds) < 6 || len(t.Reds) > maxMultiRed {
+			return fmt.Errorf("multiple ticket reds must be 6..%d", maxMultiRed)
+		}
+		if len(t.Reds) == 6 && len(t.Blues) == 1 {
+			return fmt.Errorf("multiple ticket needs 7+ reds or 2+ blues")
+		}
+	case TicketBanker:
+		if len(t.Bankers) < 1 || len(t.Bankers) > maxBankerRed {
+			return fmt.Errorf("banker count must be 1..%d", maxBankerRed)
+		}
+		if len(t.Bankers)+len(t.Reds) < 7 {
+			return fmt.Errorf("bankers + drags must be at least 7")
+		}
+		if len(t.Reds) > maxMultiRed {
+			return fmt.Errorf("drag count must be <= %d", maxMultiRed)
+		}
+		for _, b := range t.Bankers {
+			if contains(t.Reds, b) {
+				return fmt.Errorf("banker %d also in drags", b)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown ticket type: %d", t.Type)
+	}
+	return nil
+}
+
+// 注数：复式 C(红,6)×蓝；胆拖 C(拖,6-胆)×蓝
+func (t Ticket) Bets() int {
+	switch t.Type {
+	case TicketBanker:
+		return Choose(len(t.Reds), 6-len(t.Bankers)) * len(t.Blues)
+	default:
+		return Choose(len(t.Reds), 6) * len(t.Blues)
+	}
+}
+
+func (t Ticket) Cost() int { return t.Bets() * pricePerTicketYuan }
+
+// Expand：展开为全部单注（升序红球）
+func (t Ticket) Expand() []Combo {
+	need := 6
+	base := []int(nil)
+	if t.Type == TicketBanker {
+		need = 6 - len(t.Bankers)
+		base = t.Bankers
+	}
+	reds := append([]int(nil), t.Reds...)
+	sort.Ints(reds)
+	out := make([]Combo, 0, t.Bets())
+	eachSubset(reds, need, func(sub []int) {
+		r := append(append([]int(nil), base...), sub...)
+		sort.Ints(r)
+		for _, b := range t.Blues {
+			out = append(out, Combo{Reds: append([]int(nil), r...), Blue: b})
+		}
+	})
+	return out
+}
+
+// 配置对应的每张票红球规模：reds 为全部红球（胆拖为拖码）个数，bankers 为胆码个数。
+// 生成（expandTicket）、预算换算（betsPerTicket）与校验（redsPerTicket）共用同一口径
+func ticketReds(cfg Config) (reds, bankers int) {
+	switch cfg.TicketType {
+	case TicketMultiple:
+		return max(6, cfg.MultiRed), 0
+	case TicketBanker:
+		bankers = min(max(1, cfg.BankerCount), maxBankerRed)
+		return max(6-bankers, cfg.DragCount), bankers
+	default:
+		return 6, 0
+	}
+}
+
+// 配置对应的每张票注数（用于预算换算）
+func betsPerTicket(cfg Config) int {
+	if cfg.TicketType != TicketMultiple && cfg.TicketType != TicketBanker {
+		return 1
+	}
+	reds, bankers := ticketReds(cfg)
+	return Choose(reds, 6-bankers) * max(1, cfg.MultiBlue)
+}
+
+/* =============================== 与 store 互转 =============================== */
+
+func TicketFromStore(s store.Ticket) (Ticket, error) {
+	tp, ok := parseTicketType(s.Kind)
+	if !ok {
+		return Ticket{}, fmt.Errorf("unknown ticket kind: %s", s.Kind)
+	}
+	blues := s.Blues
+	if len(blues) == 0 && s.Blue > 0 {
+		blues = []int{s.Blue}
+	}
+	return Ticket{
+		Type:    tp,
+		Reds:    append([]int(nil), s.Reds...),
+		Bankers: append([]int(nil), s.Bankers...),
+		Blues:   append([]int(nil), blues...),
+	}, nil
+}
+
+func (t Ticket) Store() store.Ticket {
+	out := store.Ticket{
+		Kind:    t.Type.String(),
+		Reds:    append([]int(nil), t.Reds...),
+		Bankers: append([]int(nil), t.Bankers...),
+		Blues:   append([]int(nil), t.Blues...),
+	}
+	if len(t.Blues) > 0 {
+		out.Blue = t.Blues[0]
+	}
+	return out
+}
+
+/* =============================== 小工具 =============================== */
+
+// Choose：组合数 C(n,k)
+func Choose(n, k int) int {
+	if k < 0 || n < 0 || k > n {
+		return 0
+	}
+	if k > n-k {
+		k = n - k
+	}
+	res := 1
+	for i := 1; i <= k; i++ {
+		res = res * (n - k + i) / i
+	}
+	return res
+}
+
+func eachSubset(a []int, k int, fn func([]int)) {
+	if k < 0 || k > len(a) {
+		return
+	}
+	buf := make([]int, 0, k)
+	var rec func(start int)
+	rec = func(start int) {
+		if len(buf) == k {
+			fn(buf)
+			return
+		}
+		for i := start; i <= len(a)-(k-len(buf)); i++ {
+			buf = append(buf, a[i])
+			rec(i + 1)
+			buf = buf[:len(buf)-1]
+		}
+	}
+	rec(0)
+}
+
+func checkNumbers(a []int, lo, hi int, what string) error {
+	seen := make(map[int]struct{}, len(a))
+	for _, v := range a {
+		if v < lo || v > hi {
+			return fmt.Errorf("%s out of range: %d", what, v)
+		}
+		if _, dup := seen[v]; dup {
+			return fmt.Errorf("duplicate %s: %d", what, v)
+		}
+		seen[v] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTicketBetsMatchExpand(t *testing.T) {
+	cases := []struct {
+		name string
+		tk   Ticket
+		bets int
+	}{
+		{"single", Ticket{Type: TicketSingle, Reds: []int{1, 2, 3, 4, 5, 6}, Blues: []int{7}}, 1},
+		{"multiple 7+1", Ticket{Type: TicketMultiple, Reds: []int{1, 2, 3, 4, 5, 6, 7}, Blues: []int{1}}, 7},
+		{"multiple 6+2", Ticket{Type: TicketMultiple, Reds: []int{1, 2, 3, 4, 5, 6}, Blues: []int{1, 2}}, 2},
+		{"multiple 8+2", Ticket{Type: TicketMultiple, Reds: []int{1, 2, 3, 4, 5, 6, 7, 8}, Blues: []int{1, 2}}, 56},
+		{"multiple 20+1", Ticket{Type: TicketMultiple, Reds: seq(1, 20), Blues: []int{1}}, 38760},
+		{"banker 1+6", Ticket{Type: TicketBanker, Bankers: []int{1}, Reds: seq(2, 7), Blues: []int{1}}, 6},
+		{"banker 2+6x2", Ticket{Type: TicketBanker, Bankers: []int{1, 2}, Reds: seq(3, 8), Blues: []int{1, 2}}, 30},
+		{"banker 5+10", Ticket{Type: TicketBanker, Bankers: seq(1, 5), Reds: seq(6, 15), Blues: []int{1}}, 10},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.tk.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tc.tk.Bets(); got != tc.bets {
+				t.Fatalf("Bets = %d, want %d", got, tc.bets)
+			}
+			combos := tc.tk.Expand()
+			if len(combos) != tc.bets {
+				t.Fatalf("Expand gave %d combos, want %d", len(combos), tc.bets)
+			}
+			seen := map[string]bool{}
+			for _, c := range combos {
+				if len(c.Reds) != 6 {
+					t.Fatalf("combo %v has %d reds", c.Reds, len(c.Reds))
+				}
+				for _, b := range tc.tk.Bankers {
+					if !contains(c.Reds, b) {
+						t.Fatalf("combo %v misses banker %d", c.Reds, b)
+					}
+				}
+				key := fmt.Sprintf("%s+%d", redKeyStr(c.Reds), c.Blue)
+				if seen[key] {
+					t.Fatalf("combo %v+%d expanded twice", c.Reds, c.Blue)
+				}
+				seen[key] = true
+			}
+			if cost := tc.tk.Cost(); cost != tc.bets*pricePerTicketYuan {
+				t.Fatalf("Cost = %d, want %d", cost, tc.bets*pricePerTicketYuan)
+			}
+		})
+	}
+}
+
+func seq(lo, hi int) []int {
+	out := make([]int, 0, hi-lo+1)
+	for n := lo; n <= hi; n++ {
+		out = append(out, n)
+	}
+	return out
+}
+
+// 预算换算用的注数、校验用的红球数，须与实际生成的票一致
+func TestBetsPerTicketMatchesGenerated(t *testing.T) {
+	hist := fakeHistory(50)
+	cases := []func(*Config){
+		func(c *Config) {},
+		func(c *Config) { c.TicketType, c.MultiRed = TicketMultiple, 9 },
+		func(c *Config) { c.TicketType, c.MultiRed, c.MultiBlue = TicketMultiple, 6, 3 },
+		func(c *Config) { c.TicketType, c.BankerCount, c.DragCount = TicketBanker, 1, 6 },
+		func(c *Config) { c.TicketType, c.BankerCount, c.DragCount, c.MultiBlue = TicketBanker, 5, 12, 2 },
+	}
+	for i, edit := range cases {
+		cfg := DefaultConfig()
+		cfg.Seed, cfg.GenerateCount = 3, 3
+		edit(&cfg)
+		tickets, _, err := LuckTickets(cfg, hist)
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		for _, tk := range tickets {
+			if got, want := tk.Bets(), betsPerTicket(cfg); got != want {
+				t.Errorf("case %d: ticket has %d bets, betsPerTicket = %d", i, got, want)
+			}
+			if got, want := len(tk.Reds)+len(tk.Bankers), redsPerTicket(cfg); got != want {
+				t.Errorf("case %d: ticket has %d reds, redsPerTicket = %d", i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+/* =============================== 配置校验 =============================== */
+
+// FieldError：单个字段的校验错误；Field 为字段路径（如 Bands.MidLo、StartBuckets[1].To），供前端定位
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors：Validate 的全部错误；errors.Is(err, ErrInvalidConfig) 成立
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, fe := range e {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return ErrInvalidConfig.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e ValidationErrors) Unwrap() error { return ErrInvalidConfig }
+
+func (e *ValidationErrors) add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate：检查取值范围与相互约束，一次返回所有问题；无问题返回 nil
+func (c Config) Validate() error {
+	var errs ValidationErrors
+
+	// 选号策略
+	if c.Mode < ModeRandom || c.Mode > ModeMixed {
+		errs.add("Mode", "unknown mode %d", c.Mode)
+	}
+	if c.Animal != 0 && (c.Animal < Rat || c.Animal > Pig) {
+		errs.add("Animal", "must be 1..12 (or 0 for none)")
+	}
+	if c.Birthday != "" {
+		if _, err := time.Parse("2006-01-02", c.Birthday); err != nil {
+			errs.add("Birthday", "must be YYYY-MM-DD")
+		}
+	}
+
+	// 注数/预算
+	if c.GenerateCount <= 0 {
+		errs.add("GenerateCount", "must be > 0")
+	}
+	if c.BudgetYuan < 0 {
+		errs.add("BudgetYuan", "must be >= 0")
+	}
+
+	// 过滤/固定
+	checkNumberList(&errs, "RedFilter", c.RedFilter, 33)
+	checkNumberList(&errs, "BlueFilter", c.BlueFilter, 16)
+	checkNumberList(&errs, "FixedRed", c.FixedRed, 33)
+	blocked := toSet(c.RedFilter)
+	for i, n := range c.FixedRed {
+		if _, bad := blocked[n]; bad {
+			errs.add(fmt.Sprintf("FixedRed[%d]", i), "%d is also in RedFilter", n)
+		}
+	}
+	if needReds, left := redsPerTicket(c), 33-len(toSet(c.RedFilter)); left < needReds {
+		errs.add("RedFilter", "leaves %d reds, need at least %d", left, needReds)
+	}
+	if needBlues, left := max(1, c.MultiBlue), len(buildAvailableBlues(c.BlueFilter)); left < needBlues {
+		errs.add("BlueFilter", "leaves %d blues, need at least %d", left, needBlues)
+	}
+
+	// 幸运号策略
+	if c.FMode < FixedAlways || c.FMode > FixedRotate {
+		errs.add("FMode", "unknown fixed mode %d", c.FMode)
+	}
+	if c.FMode == FixedAlways && len(c.FixedRed) > 6 {
+		errs.add("FixedRed", "at most 6 numbers when FMode is always")
+	}
+	if c.FixedPerTicket < 0 || c.FixedPerTicket > 6 {
+		errs.add("FixedPerTicket", "must be 0..6")
+	}
+
+	// 覆盖控制
+	if c.MaxOverlapRed < 0 || c.MaxOverlapRed > 6 {
+		errs.add("MaxOverlapRed", "must be 0..6")
+	}
+
+	// 锚点配置
+	for i, b := range c.StartBuckets {
+		path := fmt.Sprintf("StartBuckets[%d]", i)
+		if b.From < 1 || b.From > 28 {
+			errs.add(path+".From", "must be 1..28")
+		}
+		if b.To > 33 || (b.To > 0 && b.To < b.From) {
+			errs.add(path+".To", "must be From..33 (or 0 for no limit)")
+		}
+		if b.Count < 0 {
+			errs.add(path+".Count", "must be >= 0")
+		}
+	}
+	if c.MaxPerAnchor < 0 {
+		errs.add("MaxPerAnchor", "must be >= 0")
+	}
+
+	// 分段
+	checkBands(&errs, c.Bands)
+	for i, t := range c.BandTemplates {
+		if t[0] < 0 || t[1] < 0 || t[2] < 0 || t[0]+t[1]+t[2] != 6 {
+			errs.add(fmt.Sprintf("BandTemplates[%d]", i), "must be non-negative and sum to 6")
+		}
+	}
+	if c.TemplateRepeat < 0 {
+		errs.add("TemplateRepeat", "must be >= 0")
+	}
+
+	// 投注方式
+	switch c.TicketType {
+	case TicketSingle:
+	case TicketMultiple:
+		if c.MultiRed < 6 || c.MultiRed > maxMultiRed {
+			errs.add("MultiRed", "must be 6..%d", maxMultiRed)
+		} else if c.MultiRed == 6 && c.MultiBlue <= 1 {
+			// 6 红 1 蓝就是单式：复式至少 7 红或 2 蓝
+			errs.add("MultiRed", "multiple ticket needs 7+ reds or 2+ blues")
+			errs.add("MultiBlue", "multiple ticket needs 7+ reds or 2+ blues")
+		}
+	case TicketBanker:
+		if c.BankerCount < 1 || c.BankerCount > maxBankerRed {
+			errs.add("BankerCount", "must be 1..%d", maxBankerRed)
+		}
+		if c.DragCount < 1 || c.DragCount > maxMultiRed {
+			errs.add("DragCount", "must be 1..%d", maxMultiRed)
+		} else if c.BankerCount+c.DragCount < 7 {
+			errs.add("DragCount", "bankers + drags must be at least 7")
+		}
+	default:
+		errs.add("TicketType", "unknown ticket type %d", c.TicketType)
+	}
+	if c.MultiBlue < 0 || c.MultiBlue > 16 {
+		errs.add("MultiBlue", "must be 0..16")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkNumberList(errs *ValidationErrors, field string, nums []int, hi int) {
+	seen := make(map[int]struct{}, len(nums))
+	for i, n := range nums {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		if n < 1 || n > hi {
+			errs.add(path, "must be 1..%d", hi)
+			continue
+		}
+		if _, dup := seen[n]; dup {
+			errs.add(path, "duplicate %d", n)
+		}
+		seen[n] = struct{}{}
+	}
+}
+
+// 三段各自在 1..33 内且 Lo<=Hi，互不重叠并完整覆盖 1..33
+func checkBands(errs *ValidationErrors, b BandRange) {
+	if b == (BandRange{}) {
+		errs.add("Bands", "required")
+		return
+	}
+	ranges := []struct {
+		name   string
+		lo, hi int
+	}{{"Low", b.LowLo, b.LowHi}, {"Mid", b.MidLo, b.MidHi}, {"High", b.HighLo, b.HighHi}}
+
+	var owner [34]string
+	ok := true
+	for _, r := range ranges {
+		if r.lo < 1 || r.lo > 33 {
+			errs.add("Bands."+r.name+"Lo", "must be 1..33")
+			ok = false
+		}
+		if r.hi < 1 || r.hi > 33 {
+			errs.add("Bands."+r.name+"Hi", "must be 1..33")
+			ok = false
+		} else if r.hi < r.lo {
+			errs.add("Bands."+r.name+"Hi", "must be >= %sLo", r.name)
+			ok = false
+		}
+	}
+	if !ok {
+		return
+	}
+	for _, r := range ranges {
+		for n := r.lo; n <= r.hi; n++ {
+			if owner[n] != "" {
+				errs.add("Bands."+r.name+"Lo", "overlaps %s at %d", owner[n], n)
+				return
+			}
+			owner[n] = r.name
+		}
+	}
+	for n := 1; n <= 33; n++ {
+		if owner[n] == "" {
+			errs.add("Bands", "%d is not covered by any band", n)
+			return
+		}
+	}
+}
+
+// 每张票需要的红球个数（过滤后至少要剩这么多）
+func redsPerTicket(c Config) int {
+	reds, bankers := ticketReds(c)
+	return reds + bankers
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"luck/backend/store"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 开奖历史：分页 / 单期查询 ===================== */
+
+// GET /api/history/draws?cursor=&limit=&order=asc|desc&from_issue=&to_issue=&from=&to=
+//
+//	&source=&contains=1,5,7&blue=&sum_min=&sum_max=
+func listDrawsHandler(c *gin.Context) {
+	q, err := drawQueryFromRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := st.QueryDraws(q)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, store.ErrBadQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func drawQueryFromRequest(c *gin.Context) (store.DrawQuery, error) {
+	q := store.DrawQuery{
+		Cursor:    c.Query("cursor"),
+		FromIssue: strings.TrimSpace(c.Query("from_issue")),
+		ToIssue:   strings.TrimSpace(c.Query("to_issue")),
+		FromDate:  strings.TrimSpace(c.Query("from")),
+		ToDate:    strings.TrimSpace(c.Query("to")),
+		Source:    strings.TrimSpace(c.Query("source")),
+	}
+	switch strings.ToLower(c.DefaultQuery("order", "desc")) {
+	case "asc":
+		q.Asc = true
+	case "desc":
+	default:
+		return q, fmt.Errorf("order must be asc or desc")
+	}
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &q.Limit}, {"blue", &q.Blue}, {"sum_min", &q.MinSum}, {"sum_max", &q.MaxSum},
+	}
+	for _, p := range ints {
+		raw := strings.TrimSpace(c.Query(p.name))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s: %q", p.name, raw)
+		}
+		*p.dst = n
+	}
+	if raw := strings.TrimSpace(c.Query("contains")); raw != "" {
+		reds, err := store.ParseReds(raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid contains: %w", err)
+		}
+		q.Contains = reds
+	}
+	return q, nil
+}
+
+// GET /api/history/draws/:issue
+func getDrawHandler(c *gin.Context) {
+	d, err := st.GetByIssue(c.Param("issue"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if d == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "draw not found"})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+/* ===================== 开奖历史：手工录入 / 更正 / 删除 ===================== */
+
+// 手工录入的来源标记
+const sourceManual = "manual"
+
+type drawInput struct {
+	Issue    string `json:"issue"` // 可省略；给出时须与路径一致
+	DrawDate string `json:"draw_date"`
+	Reds     []int  `json:"reds"`
+	Blue     int    `json:"blue"`
+	Source   string `json:"source"` // 缺省 manual
+}
+
+// 解析请求体并按 store.ValidateDraw 校验（红球先排序，重复号码会被拒绝）
+func bindDrawInput(c *gin.Context) (Draw, bool) {
+	issue := strings.TrimSpace(c.Param("issue"))
+	var in drawInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return Draw{}, false
+	}
+	if in.Issue != "" && strings.TrimSpace(in.Issue) != issue {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "issue in body does not match path"})
+		return Draw{}, false
+	}
+	d := Draw{
+		Issue:    issue,
+		DrawDate: store.NormalizeDate(in.DrawDate),
+		Reds:     append([]int(nil), in.Reds...),
+		Blue:     in.Blue,
+		Source:   strings.TrimSpace(in.Source),
+	}
+	if d.Source == "" {
+		d.Source = sourceManual
+	}
+	if _, err := time.Parse("2006-01-02", d.DrawDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid draw_date: %q", in.DrawDate)})
+		return Draw{}, false
+	}
+	sort.Ints(d.Reds)
+	if err := store.ValidateDraw(d); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return Draw{}, false
+	}
+	return d, true
+}
+
+func auditMeta(c *gin.Context) store.AuditMeta {
+	return store.AuditMeta{Actor: adminActor(c), Source: "api", Remote: c.ClientIP()}
+}
+
+func drawChangeStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrDrawExists):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrDrawNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// POST /api/history/draws/:issue
+func createDrawHandler(c *gin.Context) {
+	d, ok := bindDrawInput(c)
+	if !ok {
+		return
+	}
+	out, err := st.InsertDraw(d, auditMeta(c))
+	if err != nil {
+		c.JSON(drawChangeStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// PUT /api/history/draws/:issue
+func updateDrawHandler(c *gin.Context) {
+	d, ok := bindDrawInput(c)
+	if !ok {
+		return
+	}
+	out, err := st.UpdateDraw(d.Issue, d, auditMeta(c))
+	if err != nil {
+		c.JSON(drawChangeStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// DELETE /api/history/draws/:issue
+func deleteDrawHandler(c *gin.Context) {
+	prev, err := st.DeleteDraw(c.Param("issue"), auditMeta(c))
+	if err != nil {
+		c.JSON(drawChangeStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "deleted": prev})
+}
+
+// GET /api/history/draws/:issue/audit?limit=
+func drawAuditHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	list, err := st.ListDrawAudit(c.Param("issue"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
-	"context"
+	"bytes"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log"
 	"luck/backend/generator"
 	"luck/backend/store"
 	"math"
@@ -14,13 +17,12 @@ import (
 	"path"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"resty.dev/v3"
 )
 
 /* ===================== 与前端/第三方一致的结构 ===================== */
@@ -32,43 +34,42 @@ type Series struct{ List []Draw }
 
 /* ===================== 基础配置（可通过接口修改） ===================== */
 
-type BandRange struct{ Low, Mid, High [2]int }
-type BandTpl struct{ Vals [3]int }
-
 type AppConfig struct {
-	Port            int       `json:"port"`
-	AllowOrigins    []string  `json:"allow_origins"`
-	Count           int       `json:"count"`
-	Mode            string    `json:"mode"`
-	Animal          string    `json:"animal"`
-	Birthday        string    `json:"birthday"`
-	RedFilter       []int     `json:"red_filter"`
-	BlueFilter      []int     `json:"blue_filter"`
-	FixedRed        []int     `json:"fixed_red"`
-	FixedMode       string    `json:"fixed_mode"`
-	FixedPerTicket  int       `json:"fixed_per_ticket"`
-	MaxOverlapRed   int       `json:"max_overlap_red"`
-	UsePerNumberCap bool      `json:"use_per_number_cap"`
-	Bands           BandRange `json:"bands"`
-	BandTemplates   []BandTpl `json:"band_templates"`
-	TemplateRepeat  int       `json:"template_repeat"`
-	UseAPISource    bool      `json:"use_api_source"`
-	APIProvider     string    `json:"api_provider"`
-	APIKey          string    `json:"api_key"`
-}
+	Port         int      `json:"port"`
+	AllowOrigins []string `json:"allow_origins"`
+
+	// 生成默认值：字段平铺，与 /api/generate 的 config 同一套 schema（override=false 时使用）
+	generator.Config
+
+	UseAPISource bool   `json:"use_api_source"`
+	APIProvider  string `json:"api_provider"`
+	APIKey       string `json:"api_key"`
+	APIEndpoint  string `json:"api_endpoint"` // 覆盖第三方地址；file 源为 JSON 路径
 
-var cfg = AppConfig{
-	Port:         8080,
-	AllowOrigins: []string{"http://localhost:5173", "http://127.0.0.1:5173"},
-	Count:        10, Mode: "mixed", Animal: "Dog", Birthday: "1991-05-28",
-	RedFilter: []int{}, BlueFilter: []int{}, FixedRed: []int{},
-	FixedMode: "rotate", FixedPerTicket: 2, MaxOverlapRed: 3, UsePerNumberCap: true,
-	Bands:          BandRange{Low: [2]int{1, 11}, Mid: [2]int{12, 22}, High: [2]int{23, 33}},
-	BandTemplates:  []BandTpl{{[3]int{2, 2, 2}}, {[3]int{2, 3, 1}}, {[3]int{3, 2, 1}}, {[3]int{1, 2, 3}}, {[3]int{1, 3, 2}}},
-	TemplateRepeat: 2,
-	UseAPISource:   false, APIProvider: "jisu", APIKey: "",
+	// 多源交叉校验：APIProviders 非空时同时请求，至少 APIQuorum 个源一致才入库
+	APIProviders []string          `json:"api_providers"`
+	APIQuorum    int               `json:"api_quorum"`
+	APIKeys      map[string]string `json:"api_keys"`      // 按源名覆盖 APIKey
+	APIEndpoints map[string]string `json:"api_endpoints"` // 按源名覆盖 APIEndpoint
+
+	// 开奖日（二/四/日 21:15）后自动拉取；需同时开启 UseAPISource
+	SchedulerEnabled bool `json:"scheduler_enabled"`
 }
 
+// cfg 只能整体替换（见 config.go），不可原地修改；读取一律经 appConfig() 取快照。
+// cfgMu 只保护替换本身；cfgWriteMu 串行化“读-改-落库-替换”，落库期间读者不受阻塞
+var (
+	cfgMu      sync.RWMutex
+	cfgWriteMu sync.Mutex
+	cfg        = AppConfig{
+		Port:         8080,
+		AllowOrigins: []string{"http://localhost:5173", "http://127.0.0.1:5173"},
+		Config:       generator.DefaultConfig(),
+		UseAPISource: false, APIProvider: "mxnzp", APIKey: "", // 第三方源需凭据，显式开启
+		SchedulerEnabled: true,
+	}
+)
+
 /* ===================== 服务启动 ===================== */
 
 var st *store.Store
@@ -159,13 +160,23 @@ func main() {
 		panic(err)
 	}
 	defer st.Close()
+	st.OnDrawChanged(settleOnDraw)
+	if err := loadAppConfig(); err != nil {
+		log.Printf("load config: %v (using defaults)", err)
+	}
+
+	boot := appConfig()
+
+	sched = newDrawScheduler()
+	applySchedulerConfig(boot)
+	defer sched.Stop()
 
 	r := gin.Default()
 	serveSPAEmbedded(r)
 	// CORS（开发期放开；同域部署可收紧）
 	c := cors.Config{
-		AllowOrigins:     cfg.AllowOrigins,
-		AllowMethods:     []string{"GET", "POST", "PUT", "OPTIONS"},
+		AllowOrigins:     boot.AllowOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"*"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: false,
@@ -174,7 +185,7 @@ func main() {
 	// 若 AllowOrigins 为空则允许所有
 	if len(c.AllowOrigins) == 0 {
 		c = cors.Config{
-			AllowAllOrigins: true, AllowMethods: []string{"GET", "POST", "PUT", "OPTIONS"},
+			AllowAllOrigins: true, AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 			AllowHeaders: []string{"*"}, ExposeHeaders: []string{"Content-Length"},
 			AllowCredentials: false, MaxAge: 12 * time.Hour,
 		}
@@ -183,7 +194,7 @@ func main() {
 
 	registerRoutes(r)
 
-	addr := fmt.Sprintf(":%d", cfg.Port)
+	addr := fmt.Sprintf(":%d", boot.Port)
 	_ = r.Run(addr)
 }
 
@@ -193,33 +204,58 @@ func registerRoutes(r *gin.Engine) {
 	api := r.Group("/api")
 
 	// 配置
-	api.GET("/config", func(ctx *gin.Context) { ctx.JSON(200, cfg) })
-	api.PUT("/config", func(ctx *gin.Context) {
-		var in AppConfig
-		if err := ctx.ShouldBindJSON(&in); err != nil {
-			ctx.JSON(400, gin.H{"error": err.Error()})
-			return
-		}
-		for _, t := range in.BandTemplates {
-			if t.Vals[0]+t.Vals[1]+t.Vals[2] != 6 {
-				ctx.JSON(400, gin.H{"error": "band template must sum to 6"})
-				return
-			}
-		}
-		cfg = in
-		ctx.JSON(200, gin.H{"ok": true})
-	})
+	api.GET("/config", getConfigHandler)
+	api.PUT("/config", requireAdmin(), putConfigHandler)
 
 	// 历史 Excel 上传（sheet1；跳过前两行表头；第2列日期；第3列为7行号码）
 	api.POST("/history/upload", uploadHistoryHandler)
 	api.GET("/history/summary", historySummaryHandler)
+	api.GET("/history/export", historyExportHandler)
+	api.GET("/history/draws", listDrawsHandler)
+	api.GET("/history/draws/:issue", getDrawHandler)
+
+	// 手工维护开奖（需管理令牌），每次变更写入审计
+	admin := api.Group("", requireAdmin())
+	admin.POST("/history/draws/:issue", createDrawHandler)
+	admin.PUT("/history/draws/:issue", updateDrawHandler)
+	admin.DELETE("/history/draws/:issue", deleteDrawHandler)
+	admin.GET("/history/draws/:issue/audit", drawAuditHandler)
+	admin.GET("/admin/backup", backupHandler)
+	admin.POST("/admin/restore", restoreHandler)
+	admin.POST("/history/backfill", historyBackfillHandler) // 逐期请求第三方并写库
+	api.GET("/history/gaps", historyGapsHandler)
 
 	// 最新一期（第三方拉取 → 与 DB 对齐 → 返回第三方字段）
 	api.GET("/draw/latest", handleLatestDraw)
+	api.GET("/draw/conflicts", listDrawConflictsHandler) // 多源交叉校验的分歧记录
 
-	// 生成号码（示例：简单随机 + 与历史去重）
+	// 开奖日自动拉取
+	api.GET("/scheduler", schedulerStatusHandler)
+
+	// 生成号码（示例：简单随机 + 与历史去重）；每次生成都会记为一条 slip
 	api.POST("/generate", handleGenerate)
 
+	// 配置预设（presets）；增删改需管理令牌，每次变更写入变更记录
+	api.GET("/presets", listPresetsHandler)
+	admin.POST("/presets", createPresetHandler)
+	api.GET("/presets/:name", getPresetHandler)
+	admin.PUT("/presets/:name", updatePresetHandler)
+	admin.DELETE("/presets/:name", deletePresetHandler)
+	api.GET("/presets/:name/history", presetHistoryHandler)
+
+	// 购买记录（slips）
+	api.GET("/slips", listSlipsHandler)
+	api.POST("/slips", createSlipHandler)
+	api.GET("/slips/:id", getSlipHandler)
+	api.PUT("/slips/:id", updateSlipHandler)
+	api.DELETE("/slips/:id", deleteSlipHandler)
+	api.GET("/slips/:id/result", slipResultHandler)
+	api.POST("/slips/:id/replay", replaySlipHandler)
+	api.GET("/winnings/:issue", issueWinningsHandler)
+
+	// 回测：按历史逐期回放生成配置
+	api.POST("/backtest", backtestHandler)
+
 	// 分析
 	api.GET("/analysis/heatmap", func(ctx *gin.Context) {
 		window := atoiDefault(ctx.Query("window"), 100)
@@ -235,37 +271,82 @@ func registerRoutes(r *gin.Engine) {
 		series := seriesRecent(0)
 		ctx.JSON(200, AnalyzeSummary(series))
 	})
+	api.GET("/analysis/duplicates", func(ctx *gin.Context) {
+		list, err := st.DuplicateCombos(atoiDefault(ctx.Query("limit"), 100))
+		if err != nil {
+			ctx.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(200, list)
+	})
+	api.GET("/analysis/frequency", func(ctx *gin.Context) {
+		freq, err := st.RedFrequency()
+		if err != nil {
+			ctx.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(200, gin.H{"red": freq[1:]}) // 下标 0 对应 1 号
+	})
 }
 
 /* ===================== 历史上传 & 汇总 ===================== */
 
+// ?replace=1 覆盖导入；?merge=1 按期号增量合并；?dry_run=1 只校验不写库。无论成功与否都返回逐行校验报告。
+// 格式按内容嗅探（xlsx/csv/json/jsonl），可用 ?format= 指定；表格列映射可用 ?columns=<JSON> 指定
 func uploadHistoryHandler(c *gin.Context) {
 	fh, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	replace := c.DefaultQuery("replace", "0") == "1"
+	opt := store.ImportOptions{
+		Replace: c.DefaultQuery("replace", "0") == "1",
+		DryRun:  c.DefaultQuery("dry_run", "0") == "1",
+		Merge:   c.DefaultQuery("merge", "0") == "1",
+		Format:  c.Query("format"),
+		Audit:   uploadAuditMeta(c),
+	}
+	if raw := c.Query("columns"); raw != "" {
+		var m store.ColumnMapping
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			c.JSON(400, gin.H{"error": "invalid columns: " + err.Error()})
+			return
+		}
+		opt.Columns = &m
+	}
 
-	tmp := filepath.Join(os.TempDir(), fmt.Sprintf("hist-%d.xlsx", time.Now().UnixNano()))
+	tmp := filepath.Join(os.TempDir(), fmt.Sprintf("hist-%d%s", time.Now().UnixNano(), filepath.Ext(fh.Filename)))
 	if err := c.SaveUploadedFile(fh, tmp); err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 	defer os.Remove(tmp)
 
-	n, e := st.ImportExcel(tmp, replace)
+	rep, e := st.ImportFile(tmp, opt)
 	if e != nil {
 		// ErrAlreadyInitialized 时返回 409
-		if e.Error() == "already_initialized" {
-			c.JSON(409, gin.H{"error": "already_initialized", "msg": "历史已初始化。如需覆盖，请加 ?replace=1"})
+		if errors.Is(e, store.ErrAlreadyInitialized) {
+			c.JSON(409, gin.H{"error": "already_initialized", "msg": "历史已初始化。如需覆盖，请加 ?replace=1；增量合并请加 ?merge=1", "report": rep})
 			return
 		}
-		c.JSON(400, gin.H{"error": e.Error()})
+		c.JSON(400, gin.H{"error": e.Error(), "report": rep})
 		return
 	}
+	mode := map[bool]string{true: "replace", false: "init"}[opt.Replace]
+	if opt.Merge {
+		mode = "merge"
+	}
 	sum, _ := st.HistorySummary()
-	c.JSON(200, gin.H{"ok": true, "mode": map[bool]string{true: "replace", false: "init"}[replace], "imported": n, "summary": sum})
+	c.JSON(200, gin.H{"ok": true, "mode": mode, "dry_run": opt.DryRun, "imported": rep.Imported, "report": rep, "summary": sum})
+}
+
+// 上传不要求管理令牌；操作人取 X-Actor，缺省 anonymous
+func uploadAuditMeta(c *gin.Context) store.AuditMeta {
+	actor := strings.TrimSpace(c.GetHeader("X-Actor"))
+	if actor == "" {
+		actor = "anonymous"
+	}
+	return store.AuditMeta{Actor: actor, Source: "upload", Remote: c.ClientIP()}
 }
 
 func historySummaryHandler(c *gin.Context) {
@@ -277,41 +358,80 @@ func historySummaryHandler(c *gin.Context) {
 	c.JSON(200, sum)
 }
 
-/* ===================== 最新一期：第三方拉取 + DB 对齐 ===================== */
-
-func handleLatestDraw(c *gin.Context) {
-	ctx := c.Request.Context()
+// 导出 Content-Type；xlsx 为默认格式
+var exportTypes = map[string]string{
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"csv":  "text/csv; charset=utf-8",
+	"json": "application/json; charset=utf-8",
+}
 
-	ext, err := FetchLatestDraw(ctx) // 从第三方拉取
-	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+// GET /api/history/export?format=xlsx|csv|json&from=&to=&slips=0|1
+func historyExportHandler(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "xlsx"))
+	ctype, ok := exportTypes[format]
+	if !ok {
+		c.JSON(400, gin.H{"error": "format must be xlsx, csv or json"})
 		return
 	}
-	if ext == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "latest draw not found"})
+	withSlips := c.DefaultQuery("slips", "0") == "1"
+	if withSlips && format == "csv" {
+		c.JSON(400, gin.H{"error": "csv export does not support slips; use xlsx or json"})
 		return
 	}
-	if err := validateDraw(*ext); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid draw: " + err.Error()})
+	ex, err := st.ExportData(store.ExportOptions{From: c.Query("from"), To: c.Query("to"), Slips: withSlips})
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-	if ext.FetchedAt.IsZero() {
-		ext.FetchedAt = time.Now()
+	// 先写入内存，出错时仍可返回 JSON 错误
+	var buf bytes.Buffer
+	switch format {
+	case "xlsx":
+		err = ex.WriteXLSX(&buf)
+	case "csv":
+		err = ex.WriteCSV(&buf)
+	default:
+		err = ex.WriteJSON(&buf)
 	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	name := fmt.Sprintf("history-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	c.Data(200, ctype, buf.Bytes())
+}
 
-	// 与数据库对齐（按 issue 幂等）
-	status, prev, err := st.ReconcileIssue(store.Draw{
-		Issue:     ext.Issue,
-		DrawDate:  ext.DrawDate,
-		Reds:      ext.Reds,
-		Blue:      ext.Blue,
-		Source:    ext.Source,
-		FetchedAt: ext.FetchedAt,
-	})
+/* ===================== 最新一期：第三方拉取 + DB 对齐 ===================== */
+
+func handleLatestDraw(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// 未启用第三方源：直接返回库中最新一期
+	if !appConfig().UseAPISource {
+		d, err := st.LatestDraw()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if d == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "latest draw not found"})
+			return
+		}
+		c.JSON(http.StatusOK, d)
+		return
+	}
+
+	res, err := syncLatestDraw(ctx) // 从第三方拉取 → 与数据库对齐（按 issue 幂等）
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "persist failed: " + err.Error()})
+		body := gin.H{"error": err.Error()}
+		if res != nil && res.Quorum != nil {
+			body["quorum"] = res.Quorum
+		}
+		c.JSON(syncErrorStatus(err), body)
 		return
 	}
+	ext := res.Draw
 
 	// 默认仅返回第三方字段；若 ?verbose=1 则带上 db_status & prev
 	if c.DefaultQuery("verbose", "0") == "1" {
@@ -322,10 +442,13 @@ func handleLatestDraw(c *gin.Context) {
 			"blue":       ext.Blue,
 			"source":     ext.Source,
 			"fetched_at": ext.FetchedAt,
-			"db_status":  status, // inserted | updated | noop
+			"db_status":  res.Status, // inserted | updated | noop
 		}
-		if prev != nil && status != "noop" {
-			resp["prev"] = prev
+		if res.Prev != nil && res.Status != "noop" {
+			resp["prev"] = res.Prev
+		}
+		if res.Quorum != nil {
+			resp["quorum"] = res.Quorum
 		}
 		c.JSON(http.StatusOK, resp)
 		return
@@ -333,58 +456,6 @@ func handleLatestDraw(c *gin.Context) {
 	c.JSON(http.StatusOK, ext)
 }
 
-// 第三方拉取（你可替换为你的源/密钥）
-var FetchLatestDraw = func(ctx context.Context) (*Draw, error) {
-	client := resty.New().SetTimeout(8 * time.Second)
-	var l luck
-	_, err := client.R().SetResult(&l).SetContext(ctx).SetQueryParams(map[string]string{
-		"code":       "ssq",
-		"app_id":     "rcdrixmfzrmxho3s",
-		"app_secret": "dkJQRXNlS0dscW44cFZTbnVlbUVvdz09",
-	}).Get("https://www.mxnzp.com/api/lottery/common/latest")
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	if l.Code != 1 {
-		return nil, fmt.Errorf("invalid response code: %d", l.Code)
-	}
-	red, blue, err := ParseLineInts(l.Data.OpenCode)
-	if err != nil {
-		return nil, err
-	}
-
-	date := trimDate(l.Data.Time)
-
-	// 防守式升序
-	for i := 1; i < len(red); i++ {
-		if red[i] < red[i-1] {
-			sort.Ints(red)
-			break
-		}
-	}
-
-	return &Draw{
-		Issue:     l.Data.Expect,
-		DrawDate:  date,
-		Reds:      red,
-		Blue:      blue,
-		Source:    "crawler",
-		FetchedAt: time.Now(),
-	}, nil
-}
-
-type luck struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
-	Data struct {
-		OpenCode string `json:"openCode"`
-		Code     string `json:"code"`
-		Expect   string `json:"expect"`
-		Name     string `json:"name"`
-		Time     string `json:"time"`
-	} `json:"data"`
-}
-
 /* ===================== 生成（简单随机示例） ===================== */
 
 type Stats struct {
@@ -395,12 +466,22 @@ type Stats struct {
 	HighLow   map[string]int `json:"high_low"`
 }
 type GenerateRequest struct {
-	Override bool              `json:"override"`
-	Config   *generator.Config `json:"config,omitempty"`
+	Override bool            `json:"override"`         // false：使用服务端默认配置（忽略 config）
+	Preset   string          `json:"preset,omitempty"` // 以该预设为基础；给出 config 时覆盖到预设上（无需 override）
+	Config   json.RawMessage `json:"config,omitempty"` // override=true 时覆盖到默认配置上，可只给部分字段
+	Name     string          `json:"name,omitempty"`   // slip 名称（可空，自动命名）
+	Issue    string          `json:"issue,omitempty"`  // 目标期号（可空，默认最新一期的下一期）
 }
 type GenerateResponse struct {
-	Combos []generator.Combo `json:"combos"`
-	Stats  *Stats            `json:"stats,omitempty"`
+	Combos   []generator.Combo  `json:"combos"`  // 单式时为全部号码；复式/胆拖见 tickets
+	Tickets  []generator.Ticket `json:"tickets"` // 每张票（单式/复式/胆拖）
+	Bets     int                `json:"bets"`    // 总注数
+	CostYuan int                `json:"cost_yuan"`
+	Stats    *Stats             `json:"stats,omitempty"`
+	SlipID   int64              `json:"slip_id,omitempty"`
+	RunID    int64              `json:"run_id,omitempty"` // = slip_id；GET /api/slips/:id 可取回完整配置与种子
+	Seed     int64              `json:"seed"`             // 实际使用的种子；回填 config.Seed 即可复现
+	Issue    string             `json:"issue,omitempty"`
 }
 
 func handleGenerate(ctx *gin.Context) {
@@ -409,17 +490,73 @@ func handleGenerate(ctx *gin.Context) {
 		ctx.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	//use := cfg
-	//if req.Override && req.Config != nil {
-	//	use = *req.Config
-	//}
-	//hist, _, _ := st.HistorySetAndFreq()
-	//cfg.
-	combos, err := generator.LuckCombo(*req.Config)
+	use := appConfig().Config.Clone()
+	if req.Preset != "" {
+		var ok bool
+		if use, _, ok = loadPreset(ctx, req.Preset); !ok {
+			return
+		}
+	}
+	if req.Override || req.Preset != "" {
+		if len(req.Config) == 0 && req.Preset == "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "config required when override is true"})
+			return
+		}
+		if len(req.Config) > 0 {
+			if err := json.Unmarshal(req.Config, &use); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "bad config: " + err.Error()})
+				return
+			}
+		}
+	}
+	tickets, seed, err := generator.LuckTickets(use, st)
 	if err != nil {
+		ctx.JSON(generateErrorStatus(err), generateErrorBody(err))
 		return
 	}
-	ctx.JSON(http.StatusOK, GenerateResponse{Combos: combos, Stats: buildStats(combos, cfg.Bands)})
+	use.Seed = seed
+	sl, err := saveGeneratedSlip(req.Name, req.Issue, use, tickets)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "save slip failed: " + err.Error()})
+		return
+	}
+	resp := GenerateResponse{
+		Combos: []generator.Combo{}, Tickets: tickets,
+		Stats:  buildStats(tickets, use.Bands),
+		SlipID: sl.ID, RunID: sl.ID, Seed: seed, Issue: sl.Issue,
+	}
+	for _, t := range tickets {
+		resp.Bets += t.Bets()
+		resp.CostYuan += t.Cost()
+		if t.Type == generator.TicketSingle {
+			resp.Combos = append(resp.Combos, t.Expand()...)
+		}
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// 错误体；配置校验失败时附带 fields（字段路径 + 原因）供前端标红
+func generateErrorBody(err error) gin.H {
+	body := gin.H{"error": err.Error()}
+	var ve generator.ValidationErrors
+	if errors.As(err, &ve) {
+		body["fields"] = ve
+	}
+	return body
+}
+
+// 生成失败的 HTTP 状态：参数错 400，约束过严 422，历史读取失败 503
+func generateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, generator.ErrInvalidConfig):
+		return http.StatusBadRequest
+	case errors.Is(err, generator.ErrInfeasible):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, generator.ErrHistoryUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
 //func generateSimple(n int, hist map[string]struct{}) []Combo {
@@ -447,16 +584,17 @@ func handleGenerate(ctx *gin.Context) {
 //	return out
 //}
 
-func buildStats(combos []generator.Combo, bands BandRange) *Stats {
+// 统计票面出现的号码（复式/胆拖按票面计，不按展开后的注数加权）
+func buildStats(tickets []generator.Ticket, bands generator.BandRange) *Stats {
 	s := &Stats{
 		RedFreq: map[int]int{}, BlueFreq: map[int]int{},
 		BandShare: map[string]int{"low": 0, "mid": 0, "high": 0},
 		OddEven:   map[string]int{"odd": 0, "even": 0},
 		HighLow:   map[string]int{"low": 0, "high": 0},
 	}
-	midStart := bands.Mid[0]
-	for _, c := range combos {
-		for _, n := range c.Reds {
+	midStart := bands.MidLo
+	for _, t := range tickets {
+		for _, n := range append(append([]int(nil), t.Bankers...), t.Reds...) {
 			s.RedFreq[n]++
 			if n%2 == 0 {
 				s.OddEven["even"]++
@@ -469,15 +607,17 @@ func buildStats(combos []generator.Combo, bands BandRange) *Stats {
 				s.HighLow["high"]++
 			}
 			switch {
-			case n >= bands.Low[0] && n <= bands.Low[1]:
+			case n >= bands.LowLo && n <= bands.LowHi:
 				s.BandShare["low"]++
-			case n >= bands.Mid[0] && n <= bands.Mid[1]:
+			case n >= bands.MidLo && n <= bands.MidHi:
 				s.BandShare["mid"]++
 			default:
 				s.BandShare["high"]++
 			}
 		}
-		s.BlueFreq[c.Blue]++
+		for _, b := range t.Blues {
+			s.BlueFreq[b]++
+		}
 	}
 	return s
 }
@@ -755,52 +895,3 @@ func trimDate(s string) string {
 	}
 	return ss
 }
-
-func ParseLineInts(s string) ([]int, int, error) {
-	s = strings.TrimSpace(s)
-	left, right, ok := strings.Cut(s, "+")
-	if !ok {
-		return nil, 0, fmt.Errorf("missing '+' part")
-	}
-	// 解析红球
-	var red []int
-	for i, part := range strings.Split(left, ",") {
-		n, err := strconv.Atoi(strings.TrimSpace(part))
-		if err != nil {
-			return nil, 0, fmt.Errorf("invalid number at index %d: %v", i, err)
-		}
-		if n < 1 || n > 33 {
-			return nil, 0, fmt.Errorf("red out of range: %d", n)
-		}
-		red = append(red, n)
-	}
-	// 解析蓝球
-	blue, err := strconv.Atoi(strings.TrimSpace(right))
-	if err != nil {
-		return nil, 0, fmt.Errorf("invalid plus number: %v", err)
-	}
-	if blue < 1 || blue > 16 {
-		return nil, 0, fmt.Errorf("blue out of range: %d", blue)
-	}
-	return red, blue, nil
-}
-
-func validateDraw(d Draw) error {
-	if len(d.Reds) != 6 {
-		return fmt.Errorf("reds must be 6 numbers")
-	}
-	last := 0
-	for _, v := range d.Reds {
-		if v < 1 || v > 33 {
-			return fmt.Errorf("red out of range: %d", v)
-		}
-		if v <= last {
-			return fmt.Errorf("reds must be strictly increasing")
-		}
-		last = v
-	}
-	if d.Blue < 1 || d.Blue > 16 {
-		return fmt.Errorf("blue out of range: %d", d.Blue)
-	}
-	return nil
-}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"luck/backend/generator"
+	"luck/backend/store"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 配置预设（策略档案） ===================== */
+
+type presetRequest struct {
+	Name        string          `json:"name"`        // 仅创建时使用
+	Description *string         `json:"description"` // 更新时不给则保留原值
+	Config      json.RawMessage `json:"config"`      // 可只给部分字段
+}
+
+func listPresetsHandler(c *gin.Context) {
+	list, err := st.ListPresets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func getPresetHandler(c *gin.Context) {
+	p, err := st.GetPreset(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if p == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrPresetNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// POST /api/presets：config 覆盖到服务端默认配置上，保存完整配置（之后修改默认配置不影响预设）
+func createPresetHandler(c *gin.Context) {
+	var in presetRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	raw, ok := mergePresetConfig(c, appConfig().Config.Clone(), in.Config)
+	if !ok {
+		return
+	}
+	p := store.Preset{Name: in.Name, Config: raw}
+	if in.Description != nil {
+		p.Description = *in.Description
+	}
+	out, err := st.CreatePreset(p, auditMeta(c))
+	if err != nil {
+		c.JSON(presetChangeStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// PUT /api/presets/:name：config 覆盖到该预设当前配置上
+func updatePresetHandler(c *gin.Context) {
+	var in presetRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	base, prev, ok := loadPreset(c, c.Param("name"))
+	if !ok {
+		return
+	}
+	raw, ok := mergePresetConfig(c, base, in.Config)
+	if !ok {
+		return
+	}
+	p := store.Preset{Description: prev.Description, Config: raw}
+	if in.Description != nil {
+		p.Description = *in.Description
+	}
+	out, err := st.UpdatePreset(prev.Name, p, auditMeta(c))
+	if err != nil {
+		c.JSON(presetChangeStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func deletePresetHandler(c *gin.Context) {
+	old, err := st.DeletePreset(c.Param("name"), auditMeta(c))
+	if err != nil {
+		c.JSON(presetChangeStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "deleted": old})
+}
+
+// GET /api/presets/:name/history?limit=100：变更记录（删除后仍可查）
+func presetHistoryHandler(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	list, err := st.ListPresetHistory(c.Param("name"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// loadPreset：预设配置覆盖到服务端默认配置上（旧预设缺少的新字段取默认值）；失败时已写响应
+func loadPreset(c *gin.Context, name string) (generator.Config, *store.Preset, bool) {
+	use := appConfig().Config.Clone()
+	p, err := st.GetPreset(name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return use, nil, false
+	}
+	if p == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrPresetNotFound.Error()})
+		return use, nil, false
+	}
+	if err := json.Unmarshal(p.Config, &use); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "bad stored preset: " + err.Error()})
+		return use, nil, false
+	}
+	return use, p, true
+}
+
+// 局部配置覆盖到 base 上并校验，返回完整配置的 JSON；失败时已写响应
+func mergePresetConfig(c *gin.Context, base generator.Config, patch json.RawMessage) (json.RawMessage, bool) {
+	if len(patch) > 0 {
+		if err := json.Unmarshal(patch, &base); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad config: " + err.Error()})
+			return nil, false
+		}
+	}
+	if err := base.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, generateErrorBody(err))
+		return nil, false
+	}
+	raw, err := json.Marshal(base)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return raw, true
+}
+
+func presetChangeStatus(err error) int {
+	switch {
+	case errors.Is(err, store.ErrPresetExists):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrPresetNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrInvalidPreset):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"luck/backend/generator"
+	"luck/backend/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 用临时库替换全局 st，返回已注册全部路由的引擎
+func presetTestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv(adminTokenEnv, "tok")
+	s, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := st
+	st = s
+	t.Cleanup(func() {
+		st = prev
+		_ = s.Close()
+	})
+	r := gin.New()
+	registerRoutes(r)
+	return r
+}
+
+// token 为空时不带令牌
+func doJSON(t *testing.T, r *gin.Engine, method, path, token, actor, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if actor != "" {
+		req.Header.Set("X-Actor", actor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if out != nil && w.Code < 300 {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, path, err, w.Body)
+		}
+	}
+	return w.Code
+}
+
+func presetConfig(t *testing.T, p store.Preset) generator.Config {
+	t.Helper()
+	var c generator.Config
+	if err := json.Unmarshal(p.Config, &c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// 增删改需管理令牌；读取与变更记录公开
+func TestPresetWritesRequireAdmin(t *testing.T) {
+	r := presetTestServer(t)
+	body := `{"name":"广覆盖","config":{"GenerateCount":2}}`
+	for _, token := range []string{"", "wrong"} {
+		if code := doJSON(t, r, "POST", "/api/presets", token, "", body, nil); code != http.StatusUnauthorized {
+			t.Fatalf("create with token %q: %d", token, code)
+		}
+	}
+	if code := doJSON(t, r, "POST", "/api/presets", "tok", "", body, nil); code != http.StatusCreated {
+		t.Fatalf("create as admin: %d", code)
+	}
+	if code := doJSON(t, r, "PUT", "/api/presets/广覆盖", "", "", `{"config":{"GenerateCount":3}}`, nil); code != http.StatusUnauthorized {
+		t.Fatalf("update without token: %d", code)
+	}
+	if code := doJSON(t, r, "DELETE", "/api/presets/广覆盖", "", "", "", nil); code != http.StatusUnauthorized {
+		t.Fatalf("delete without token: %d", code)
+	}
+	for _, path := range []string{"/api/presets", "/api/presets/广覆盖", "/api/presets/广覆盖/history"} {
+		if code := doJSON(t, r, "GET", path, "", "", "", nil); code != http.StatusOK {
+			t.Fatalf("GET %s: %d", path, code)
+		}
+	}
+
+	// 未配置令牌：写接口一律 403
+	t.Setenv(adminTokenEnv, "")
+	if code := doJSON(t, r, "POST", "/api/presets", "tok", "", `{"name":"x"}`, nil); code != http.StatusForbidden {
+		t.Fatalf("create with admin disabled: %d", code)
+	}
+}
+
+func TestPresetLifecycleAndHistory(t *testing.T) {
+	r := presetTestServer(t)
+	def := appConfig().Config
+
+	// 新建：局部配置覆盖到默认配置上，保存完整配置
+	var p store.Preset
+	code := doJSON(t, r, "POST", "/api/presets", "tok", "alice",
+		`{"name":"生日组","description":"生日蓝球","config":{"GenerateCount":2,"Seed":7}}`, &p)
+	if code != http.StatusCreated {
+		t.Fatalf("create: %d", code)
+	}
+	if c := presetConfig(t, p); c.GenerateCount != 2 || c.Seed != 7 || c.Mode != def.Mode || c.MultiRed != def.MultiRed {
+		t.Fatalf("created config = %+v", c)
+	}
+	if p.Description != "生日蓝球" {
+		t.Fatalf("created = %+v", p)
+	}
+
+	if code := doJSON(t, r, "POST", "/api/presets", "tok", "", `{"name":"生日组"}`, nil); code != http.StatusConflict {
+		t.Fatalf("duplicate: %d", code)
+	}
+	if code := doJSON(t, r, "POST", "/api/presets", "tok", "", `{"name":"坏的","config":{"GenerateCount":0}}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("invalid config: %d", code)
+	}
+	if code := doJSON(t, r, "PUT", "/api/presets/不存在", "tok", "", `{}`, nil); code != http.StatusNotFound {
+		t.Fatalf("update missing: %d", code)
+	}
+
+	// 修改：覆盖到预设当前配置上，description 不给则保留；内容不变的修改不记录
+	code = doJSON(t, r, "PUT", "/api/presets/生日组", "tok", "bob", `{"config":{"GenerateCount":4}}`, &p)
+	if code != http.StatusOK {
+		t.Fatalf("update: %d", code)
+	}
+	if c := presetConfig(t, p); c.GenerateCount != 4 || c.Seed != 7 || p.Description != "生日蓝球" {
+		t.Fatalf("updated = %+v, %+v", p, c)
+	}
+	if code := doJSON(t, r, "PUT", "/api/presets/生日组", "tok", "bob", `{"config":{"GenerateCount":4}}`, nil); code != http.StatusOK {
+		t.Fatalf("no-op update: %d", code)
+	}
+
+	var list []store.Preset
+	if code := doJSON(t, r, "GET", "/api/presets", "", "", "", &list); code != http.StatusOK || len(list) != 1 {
+		t.Fatalf("list = %d, %+v", code, list)
+	}
+
+	if code := doJSON(t, r, "DELETE", "/api/presets/生日组", "tok", "", "", nil); code != http.StatusOK {
+		t.Fatalf("delete: %d", code)
+	}
+	if code := doJSON(t, r, "GET", "/api/presets/生日组", "", "", "", nil); code != http.StatusNotFound {
+		t.Fatalf("get after delete: %d", code)
+	}
+	if code := doJSON(t, r, "DELETE", "/api/presets/生日组", "tok", "", "", nil); code != http.StatusNotFound {
+		t.Fatalf("delete twice: %d", code)
+	}
+
+	// 变更记录：删除后仍可查，按时间倒序；操作人取 X-Actor，缺省 admin
+	var hist []store.PresetChange
+	if code := doJSON(t, r, "GET", "/api/presets/生日组/history", "", "", "", &hist); code != http.StatusOK {
+		t.Fatalf("history: %d", code)
+	}
+	if len(hist) != 3 {
+		t.Fatalf("history = %+v", hist)
+	}
+	del, upd, cre := hist[0], hist[1], hist[2]
+	if cre.Action != "create" || cre.Actor != "alice" || cre.Source != "api" || cre.Old != nil || cre.New == nil {
+		t.Errorf("create entry = %+v", cre)
+	}
+	if upd.Action != "update" || upd.Actor != "bob" || upd.Old == nil || upd.New == nil ||
+		presetConfig(t, *upd.Old).GenerateCount != 2 || presetConfig(t, *upd.New).GenerateCount != 4 {
+		t.Errorf("update entry = %+v", upd)
+	}
+	if del.Action != "delete" || del.Actor != "admin" || del.Old == nil || del.New != nil {
+		t.Errorf("delete entry = %+v", del)
+	}
+
+	if code := doJSON(t, r, "GET", "/api/presets/生日组/history?limit=1", "", "", "", &hist); code != http.StatusOK || len(hist) != 1 || hist[0].Action != "delete" {
+		t.Fatalf("history limit 1 = %d, %+v", code, hist)
+	}
+}
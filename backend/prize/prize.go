@@ -0,0 +1,182 @@
+package prize
+
+import (
+	"luck/backend/generator"
+	"luck/backend/store"
+)
+
+// 每注 2 元
+const PricePerBet = 2
+
+// 奖级：0=未中奖；1..6 对应一至六等奖
+type Tier int
+
+const (
+	None Tier = iota
+	First
+	Second
+	Third
+	Fourth
+	Fifth
+	Sixth
+)
+
+var tierNames = [...]string{"未中奖", "一等奖", "二等奖", "三等奖", "四等奖", "五等奖", "六等奖"}
+
+func (t Tier) String() string {
+	if t < None || t > Sixth {
+		return "unknown"
+	}
+	return tierNames[t]
+}
+
+// 一、二等奖为浮动奖金（按当期奖池分配），此处记 0 并标记 Floating
+var fixedAmount = map[Tier]int{
+	Third:  3000,
+	Fourth: 200,
+	Fifth:  10,
+	Sixth:  5,
+}
+
+func (t Tier) Floating() bool { return t == First || t == Second }
+
+// 固定奖金（元）；浮动奖级返回 0
+func (t Tier) Amount() int { return fixedAmount[t] }
+
+// 单注兑奖结果
+type Result struct {
+	RedHits  int    `json:"red_hits"`
+	BlueHit  bool   `json:"blue_hit"`
+	Tier     Tier   `json:"tier"`
+	TierName string `json:"tier_name"`
+	Amount   int    `json:"amount_yuan"` // 固定奖金；浮动奖级为 0
+	Floating bool   `json:"floating"`
+}
+
+/* ---------- 奖级规则（红球命中数 + 蓝球是否命中） ---------- */
+
+// 一等奖 6+1；二等奖 6+0；三等奖 5+1；四等奖 5+0 / 4+1；
+// 五等奖 4+0 / 3+1；六等奖 2+1 / 1+1 / 0+1
+func TierOf(redHits int, blueHit bool) Tier {
+	switch {
+	case redHits == 6 && blueHit:
+		return First
+	case redHits == 6:
+		return Second
+	case redHits == 5 && blueHit:
+		return Third
+	case redHits == 5 || (redHits == 4 && blueHit):
+		return Fourth
+	case redHits == 4 || (redHits == 3 && blueHit):
+		return Fifth
+	case blueHit:
+		return Sixth
+	default:
+		return None
+	}
+}
+
+/* ---------- 兑奖 ---------- */
+
+func Check(reds []int, blue int, d store.Draw) Result {
+	win := make(map[int]struct{}, len(d.Reds))
+	for _, v := range d.Reds {
+		win[v] = struct{}{}
+	}
+	hits := 0
+	for _, v := range reds {
+		if _, ok := win[v]; ok {
+			hits++
+		}
+	}
+	blueHit := blue == d.Blue
+	t := TierOf(hits, blueHit)
+	return Result{
+		RedHits:  hits,
+		BlueHit:  blueHit,
+		Tier:     t,
+		TierName: t.String(),
+		Amount:   t.Amount(),
+		Floating: t.Floating(),
+	}
+}
+
+func CheckCombo(c generator.Combo, d store.Draw) Result { return Check(c.Reds, c.Blue, d) }
+
+/* ---------- 复式 / 胆拖：按组合数直接统计各奖级注数，无需逐注展开 ---------- */
+
+type TicketResult struct {
+	Bets       int          `json:"bets"`
+	Best       Result       `json:"best"`        // 奖级最高的一注
+	TierCounts map[Tier]int `json:"tier_counts"` // 各奖级中奖注数
+	Amount     int          `json:"amount_yuan"` // 固定奖金合计
+	Floating   int          `json:"floating"`    // 一/二等奖注数（奖金另计）
+}
+
+func CheckTicket(t generator.Ticket, d store.Draw) TicketResult {
+	win := make(map[int]struct{}, len(d.Reds))
+	for _, v := range d.Reds {
+		win[v] = struct{}{}
+	}
+	countHits := func(a []int) int {
+		n := 0
+		for _, v := range a {
+			if _, ok := win[v]; ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	// 胆码全选；从拖码（单式/复式即全部红球）中再选 need 个
+	nb, hb := len(t.Bankers), countHits(t.Bankers)
+	nd, hd := len(t.Reds), countHits(t.Reds)
+	need := 6 - nb
+
+	blueHit := 0
+	for _, b := range t.Blues {
+		if b == d.Blue {
+			blueHit = 1
+		}
+	}
+	blueMiss := len(t.Blues) - blueHit
+
+	out := TicketResult{Bets: t.Bets(), TierCounts: map[Tier]int{}}
+	bestHits := -1
+	for k := 0; k <= min(need, hd); k++ {
+		ways := generator.Choose(hd, k) * generator.Choose(nd-hd, need-k)
+		if ways == 0 {
+			continue
+		}
+		hits := hb + k
+		bestHits = max(bestHits, hits)
+		for _, bh := range []struct {
+			hit bool
+			n   int
+		}{{true, blueHit}, {false, blueMiss}} {
+			if bh.n == 0 {
+				continue
+			}
+			tier := TierOf(hits, bh.hit)
+			if tier == None {
+				continue
+			}
+			cnt := ways * bh.n
+			out.TierCounts[tier] += cnt
+			out.Amount += cnt * tier.Amount()
+			if tier.Floating() {
+				out.Floating += cnt
+			}
+			if out.Best.Tier == None || tier < out.Best.Tier {
+				out.Best = Result{RedHits: hits, BlueHit: bh.hit, Tier: tier}
+			}
+		}
+	}
+	if out.Best.Tier == None {
+		out.Best = Result{RedHits: max(bestHits, 0), BlueHit: blueHit > 0}
+	}
+	out.Best.TierName = out.Best.Tier.String()
+	out.Best.Amount = out.Best.Tier.Amount()
+	out.Best.Floating = out.Best.Tier.Floating()
+	return out
+}
@@ -0,0 +1,154 @@
+package prize
+
+import (
+	"math/rand"
+	"testing"
+
+	"luck/backend/generator"
+	"luck/backend/store"
+)
+
+var draw = store.Draw{Issue: "2024098", Reds: []int{3, 9, 14, 20, 27, 31}, Blue: 12}
+
+func TestTierOf(t *testing.T) {
+	want := map[int][2]Tier{ // 红球命中数 → {蓝未中, 蓝中}
+		6: {Second, First},
+		5: {Fourth, Third},
+		4: {Fifth, Fourth},
+		3: {None, Fifth},
+		2: {None, Sixth},
+		1: {None, Sixth},
+		0: {None, Sixth},
+	}
+	for hits := 0; hits <= 6; hits++ {
+		for i, blue := range []bool{false, true} {
+			if got := TierOf(hits, blue); got != want[hits][i] {
+				t.Errorf("TierOf(%d, %v) = %v, want %v", hits, blue, got, want[hits][i])
+			}
+		}
+	}
+}
+
+func TestCheckEachTier(t *testing.T) {
+	cases := []struct {
+		reds   []int
+		blue   int
+		tier   Tier
+		amount int
+	}{
+		{[]int{3, 9, 14, 20, 27, 31}, 12, First, 0},
+		{[]int{3, 9, 14, 20, 27, 31}, 1, Second, 0},
+		{[]int{3, 9, 14, 20, 27, 33}, 12, Third, 3000},
+		{[]int{3, 9, 14, 20, 27, 33}, 1, Fourth, 200},
+		{[]int{3, 9, 14, 20, 32, 33}, 12, Fourth, 200},
+		{[]int{3, 9, 14, 20, 32, 33}, 1, Fifth, 10},
+		{[]int{3, 9, 14, 1, 32, 33}, 12, Fifth, 10},
+		{[]int{3, 9, 2, 1, 32, 33}, 12, Sixth, 5},
+		{[]int{3, 4, 2, 1, 32, 33}, 12, Sixth, 5},
+		{[]int{5, 4, 2, 1, 32, 33}, 12, Sixth, 5},
+		{[]int{3, 9, 14, 1, 32, 33}, 1, None, 0},
+	}
+	for _, tc := range cases {
+		r := Check(tc.reds, tc.blue, draw)
+		if r.Tier != tc.tier || r.Amount != tc.amount || r.Floating != tc.tier.Floating() {
+			t.Errorf("Check(%v+%d) = %v/%d, want %v/%d", tc.reds, tc.blue, r.Tier, r.Amount, tc.tier, tc.amount)
+		}
+		// 单式票走组合统计，结果应与单注兑奖一致
+		tr := CheckTicket(generator.Ticket{Type: generator.TicketSingle, Reds: tc.reds, Blues: []int{tc.blue}}, draw)
+		if tr.Best.Tier != tc.tier || tr.Amount != tc.amount {
+			t.Errorf("CheckTicket(%v+%d) = %v/%d, want %v/%d", tc.reds, tc.blue, tr.Best.Tier, tr.Amount, tc.tier, tc.amount)
+		}
+	}
+}
+
+// 逐注展开兑奖，作为 CheckTicket 组合统计的对照
+func bruteForce(tk generator.Ticket, d store.Draw) TicketResult {
+	out := TicketResult{TierCounts: map[Tier]int{}}
+	for _, c := range tk.Expand() {
+		out.Bets++
+		r := CheckCombo(c, d)
+		if r.Tier == None {
+			continue
+		}
+		out.TierCounts[r.Tier]++
+		out.Amount += r.Amount
+		if r.Floating {
+			out.Floating++
+		}
+		if out.Best.Tier == None || r.Tier < out.Best.Tier {
+			out.Best = r
+		}
+	}
+	return out
+}
+
+func randomTicket(r *rand.Rand) generator.Ticket {
+	// 先放 k 个开奖红球再补其他号，保证各奖级都能被覆盖
+	var hits, others []int
+	for n := 1; n <= 33; n++ {
+		if contains(draw.Reds, n) {
+			hits = append(hits, n)
+		} else {
+			others = append(others, n)
+		}
+	}
+	r.Shuffle(len(hits), func(i, j int) { hits[i], hits[j] = hits[j], hits[i] })
+	r.Shuffle(len(others), func(i, j int) { others[i], others[j] = others[j], others[i] })
+	pool := append(hits[:r.Intn(7)], others...)
+
+	blues := []int{1 + r.Intn(16)}
+	if r.Intn(2) == 0 {
+		blues[0] = draw.Blue
+	}
+	for extra := r.Intn(3); extra > 0; extra-- {
+		if b := 1 + r.Intn(16); !contains(blues, b) {
+			blues = append(blues, b)
+		}
+	}
+
+	if r.Intn(2) == 0 {
+		reds := append([]int(nil), pool[:6+r.Intn(6)]...)
+		return generator.Ticket{Type: generator.TicketMultiple, Reds: reds, Blues: blues}
+	}
+	nb := 1 + r.Intn(5)
+	nd := max(7-nb, 2+r.Intn(8))
+	sel := append([]int(nil), pool[:nb+nd]...)
+	r.Shuffle(len(sel), func(i, j int) { sel[i], sel[j] = sel[j], sel[i] })
+	return generator.Ticket{Type: generator.TicketBanker, Bankers: sel[:nb], Reds: sel[nb:], Blues: blues}
+}
+
+func TestCheckTicketMatchesExpansion(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	seen := map[Tier]bool{}
+	for i := 0; i < 500; i++ {
+		tk := randomTicket(r)
+		if err := tk.Validate(); err != nil {
+			continue // 例如 6 红 1 蓝的“复式”
+		}
+		got, want := CheckTicket(tk, draw), bruteForce(tk, draw)
+		if got.Bets != want.Bets || got.Amount != want.Amount || got.Floating != want.Floating ||
+			got.Best.Tier != want.Best.Tier || len(got.TierCounts) != len(want.TierCounts) {
+			t.Fatalf("%+v:\n got  %+v\n want %+v", tk, got, want)
+		}
+		for tier, n := range want.TierCounts {
+			if got.TierCounts[tier] != n {
+				t.Fatalf("%+v: tier %v count = %d, want %d", tk, tier, got.TierCounts[tier], n)
+			}
+			seen[tier] = true
+		}
+	}
+	for tier := First; tier <= Sixth; tier++ {
+		if !seen[tier] {
+			t.Errorf("random tickets never hit %v; widen the generator", tier)
+		}
+	}
+}
+
+func contains(a []int, x int) bool {
+	for _, v := range a {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package prize
+
+import (
+	"luck/backend/generator"
+	"luck/backend/store"
+)
+
+/* ---------- 兑奖落库 ---------- */
+
+// ScoreSlip：按开奖号码为一条 slip 的全部注兑奖，并整体替换已存结果
+func ScoreSlip(st *store.Store, sl store.Slip, d store.Draw) ([]store.TicketResult, error) {
+	out, err := CheckSlip(sl, d)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.SaveSlipResults(sl.ID, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CheckSlip：只计算一条 slip 的兑奖结果，不落库
+func CheckSlip(sl store.Slip, d store.Draw) ([]store.TicketResult, error) {
+	out := make([]store.TicketResult, 0, len(sl.Tickets))
+	for i, raw := range sl.Tickets {
+		t, err := generator.TicketFromStore(raw)
+		if err != nil {
+			return nil, err
+		}
+		r := CheckTicket(t, d)
+		counts := make(map[int]int, len(r.TierCounts))
+		for tier, n := range r.TierCounts {
+			counts[int(tier)] = n
+		}
+		out = append(out, store.TicketResult{
+			SlipID:     sl.ID,
+			Idx:        i,
+			Issue:      d.Issue,
+			RedHits:    r.Best.RedHits,
+			BlueHit:    r.Best.BlueHit,
+			Tier:       int(r.Best.Tier),
+			PrizeYuan:  r.Amount,
+			Bets:       r.Bets,
+			TierCounts: counts,
+		})
+	}
+	return out, nil
+}
+
+// ScoreIssue：为目标期号为 d.Issue 的全部 slip 兑奖；返回处理的 slip 数
+func ScoreIssue(st *store.Store, d store.Draw) (int, error) {
+	if d.Issue == "" {
+		return 0, nil
+	}
+	slips, err := st.ListSlips(d.Issue)
+	if err != nil {
+		return 0, err
+	}
+	for _, sl := range slips {
+		if _, err := ScoreSlip(st, sl, d); err != nil {
+			return 0, err
+		}
+	}
+	return len(slips), nil
+}
+
+/* ---------- 汇总 ---------- */
+
+type SlipSummary struct {
+	SlipID    int64  `json:"slip_id"`
+	Name      string `json:"name"`
+	Bets      int    `json:"bets"`
+	CostYuan  int    `json:"cost_yuan"`
+	PrizeYuan int    `json:"prize_yuan"`
+	Floating  int    `json:"floating"` // 一/二等奖注数（奖金另计）
+	BestTier  Tier   `json:"best_tier"`
+}
+
+type IssueSummary struct {
+	Issue      string        `json:"issue"`
+	Draw       *store.Draw   `json:"draw,omitempty"`
+	Settled    bool          `json:"settled"` // 是否已开奖
+	Slips      int           `json:"slips"`
+	Bets       int           `json:"bets"`
+	CostYuan   int           `json:"cost_yuan"`
+	PrizeYuan  int           `json:"prize_yuan"` // 仅固定奖金
+	Floating   int           `json:"floating"`   // 一/二等奖注数（浮动奖金未计入）
+	TierCounts map[Tier]int  `json:"tier_counts"`
+	PerSlip    []SlipSummary `json:"per_slip"`
+}
+
+// Summarize：某期全部 slip 的盈亏汇总；d 为 nil 表示尚未开奖
+func Summarize(issue string, d *store.Draw, slips []store.Slip, results []store.TicketResult) IssueSummary {
+	out := IssueSummary{
+		Issue:      issue,
+		Draw:       d,
+		Settled:    d != nil,
+		Slips:      len(slips),
+		TierCounts: map[Tier]int{},
+		PerSlip:    make([]SlipSummary, 0, len(slips)),
+	}
+	bySlip := make(map[int64][]store.TicketResult, len(slips))
+	for _, r := range results {
+		bySlip[r.SlipID] = append(bySlip[r.SlipID], r)
+	}
+	for _, sl := range slips {
+		ss := SlipSummary{SlipID: sl.ID, Name: sl.Name}
+		for _, raw := range sl.Tickets {
+			if t, err := generator.TicketFromStore(raw); err == nil {
+				ss.Bets += t.Bets()
+			}
+		}
+		ss.CostYuan = ss.Bets * PricePerBet
+		for _, r := range bySlip[sl.ID] {
+			ss.PrizeYuan += r.PrizeYuan
+			for tier, n := range r.TierCounts {
+				t := Tier(tier)
+				if t == None {
+					continue
+				}
+				out.TierCounts[t] += n
+				if t.Floating() {
+					ss.Floating += n
+				}
+				if ss.BestTier == None || t < ss.BestTier {
+					ss.BestTier = t
+				}
+			}
+		}
+		out.Bets += ss.Bets
+		out.CostYuan += ss.CostYuan
+		out.PrizeYuan += ss.PrizeYuan
+		out.Floating += ss.Floating
+		out.PerSlip = append(out.PerSlip, ss)
+	}
+	return out
+}
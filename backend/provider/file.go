@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"luck/backend/store"
+	"os"
+)
+
+/* =============================== 本地 JSON 文件 =============================== */
+
+// 离线/演示用：Endpoint 为 JSON 文件路径，内容为一期 store.Draw 或其数组（取日期/期号最大者）。
+// 每次 Latest 都重新读取文件，可直接替换文件模拟开奖。
+type file struct {
+	path string
+}
+
+func init() { Register(newFile, "file", "fixture") }
+
+func newFile(opt Options) (DrawProvider, error) {
+	if opt.Endpoint == "" {
+		return nil, fmt.Errorf("file: api_endpoint (json path) required")
+	}
+	return &file{path: opt.Endpoint}, nil
+}
+
+func (p *file) Name() string { return "file" }
+
+func (p *file) Latest(ctx context.Context) (*store.Draw, error) {
+	body, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("file: %w", err)
+	}
+	return parseFile(body, "")
+}
+
+func (p *file) ByIssue(ctx context.Context, issue string) (*store.Draw, error) {
+	body, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("file: %w", err)
+	}
+	return parseFile(body, issue)
+}
+
+// issue 为空取最新一期，否则取指定期号
+func parseFile(body []byte, issue string) (*store.Draw, error) {
+	body = bytes.TrimSpace(body)
+	var list []store.Draw
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("file: decode: %w", err)
+		}
+	} else {
+		var d store.Draw
+		if err := json.Unmarshal(body, &d); err != nil {
+			return nil, fmt.Errorf("file: decode: %w", err)
+		}
+		list = append(list, d)
+	}
+	var latest *store.Draw
+	for i := range list {
+		d := &list[i]
+		d.DrawDate = store.NormalizeDate(d.DrawDate)
+		if issue != "" {
+			if d.Issue == issue {
+				return finish(*d, "file"), nil
+			}
+			continue
+		}
+		if latest == nil || d.DrawDate > latest.DrawDate ||
+			(d.DrawDate == latest.DrawDate && d.Issue > latest.Issue) {
+			latest = d
+		}
+	}
+	if latest == nil || latest.Issue == "" {
+		return nil, nil
+	}
+	return finish(*latest, "file"), nil
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"luck/backend/store"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/* =============================== jisuapi.com =============================== */
+
+const (
+	jisuEndpoint = "https://api.jisuapi.com/caipiao/query"
+	jisuSSQ      = "11" // caipiaoid：双色球
+)
+
+// 凭据：APIKey 为 appkey；为空时读环境变量 JISU_APPKEY
+type jisu struct {
+	appKey string
+	opt    Options
+}
+
+func init() { Register(newJisu, "jisu", "jisuapi") }
+
+func newJisu(opt Options) (DrawProvider, error) {
+	key := firstNonEmpty(opt.APIKey, os.Getenv("JISU_APPKEY"))
+	if key == "" {
+		return nil, fmt.Errorf("jisu: %w (api_key=appkey or JISU_APPKEY)", ErrNoCredentials)
+	}
+	return &jisu{appKey: key, opt: opt}, nil
+}
+
+func (p *jisu) Name() string { return "jisu" }
+
+func (p *jisu) Latest(ctx context.Context) (*store.Draw, error) {
+	resp, err := p.opt.client().R().SetContext(ctx).SetQueryParams(map[string]string{
+		"appkey":    p.appKey,
+		"caipiaoid": jisuSSQ,
+	}).Get(firstNonEmpty(p.opt.Endpoint, jisuEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("jisu: request failed: %w", err)
+	}
+	return parseJisu(resp.Bytes())
+}
+
+func (p *jisu) ByIssue(ctx context.Context, issue string) (*store.Draw, error) {
+	resp, err := p.opt.client().R().SetContext(ctx).SetQueryParams(map[string]string{
+		"appkey":    p.appKey,
+		"caipiaoid": jisuSSQ,
+		"issueno":   issue,
+	}).Get(firstNonEmpty(p.opt.Endpoint, jisuEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("jisu: request failed: %w", err)
+	}
+	return parseJisu(resp.Bytes())
+}
+
+type jisuResp struct {
+	Status json.RawMessage `json:"status"` // 0 成功；新旧接口分别为数字/字符串
+	Msg    string          `json:"msg"`
+	Result json.RawMessage `json:"result"` // 出错时为 "" 而非对象，先看 status 再解析
+}
+
+type jisuResult struct {
+	CaipiaoID   string `json:"caipiaoid"`
+	IssueNo     string `json:"issueno"`
+	Number      string `json:"number"`      // "01 05 12 18 25 31"
+	ReferNumber string `json:"refernumber"` // 蓝球 "09"
+	OpenDate    string `json:"opendate"`
+}
+
+func parseJisu(body []byte) (*store.Draw, error) {
+	var l jisuResp
+	if err := json.Unmarshal(body, &l); err != nil {
+		return nil, fmt.Errorf("jisu: decode: %w", err)
+	}
+	status, err := strconv.Atoi(strings.Trim(string(l.Status), `" `))
+	if err != nil {
+		return nil, fmt.Errorf("jisu: invalid status: %s", l.Status)
+	}
+	if status != 0 {
+		return nil, fmt.Errorf("jisu: invalid response status: %d %s", status, l.Msg)
+	}
+	var r jisuResult
+	if len(l.Result) > 0 && string(l.Result) != "null" {
+		if err := json.Unmarshal(l.Result, &r); err != nil {
+			return nil, fmt.Errorf("jisu: decode result: %w", err)
+		}
+	}
+	if r.IssueNo == "" {
+		return nil, nil
+	}
+	red, err := store.ParseReds(r.Number)
+	if err != nil {
+		return nil, fmt.Errorf("jisu: %w", err)
+	}
+	blue, err := strconv.Atoi(strings.TrimSpace(r.ReferNumber))
+	if err != nil || blue < 1 || blue > 16 {
+		return nil, fmt.Errorf("jisu: invalid blue: %q", r.ReferNumber)
+	}
+	return finish(store.Draw{
+		Issue:    r.IssueNo,
+		DrawDate: r.OpenDate,
+		Reds:     red,
+		Blue:     blue,
+	}, sourceCrawler), nil
+}
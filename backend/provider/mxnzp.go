@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"luck/backend/store"
+	"os"
+	"strings"
+)
+
+/* =============================== mxnzp.com =============================== */
+
+const (
+	mxnzpEndpoint      = "https://www.mxnzp.com/api/lottery/common/latest"
+	mxnzpIssueEndpoint = "https://www.mxnzp.com/api/lottery/common/aim_lottery"
+)
+
+// 凭据：APIKey 为 "app_id:app_secret"；为空时读环境变量 MXNZP_APP_ID / MXNZP_APP_SECRET
+type mxnzp struct {
+	appID, secret string
+	opt           Options
+}
+
+func init() { Register(newMxnzp, "mxnzp") }
+
+func newMxnzp(opt Options) (DrawProvider, error) {
+	id, secret, _ := strings.Cut(opt.APIKey, ":")
+	id = firstNonEmpty(id, os.Getenv("MXNZP_APP_ID"))
+	secret = firstNonEmpty(secret, os.Getenv("MXNZP_APP_SECRET"))
+	if id == "" || secret == "" {
+		return nil, fmt.Errorf("mxnzp: %w (api_key=app_id:app_secret or MXNZP_APP_ID/MXNZP_APP_SECRET)", ErrNoCredentials)
+	}
+	return &mxnzp{appID: id, secret: secret, opt: opt}, nil
+}
+
+func (p *mxnzp) Name() string { return "mxnzp" }
+
+func (p *mxnzp) Latest(ctx context.Context) (*store.Draw, error) {
+	resp, err := p.opt.client().R().SetContext(ctx).SetQueryParams(map[string]string{
+		"code":       "ssq",
+		"app_id":     p.appID,
+		"app_secret": p.secret,
+	}).Get(firstNonEmpty(p.opt.Endpoint, mxnzpEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("mxnzp: request failed: %w", err)
+	}
+	return parseMxnzp(resp.Bytes())
+}
+
+// ByIssue：指定期号；Endpoint 覆盖时两种查询共用同一地址
+func (p *mxnzp) ByIssue(ctx context.Context, issue string) (*store.Draw, error) {
+	resp, err := p.opt.client().R().SetContext(ctx).SetQueryParams(map[string]string{
+		"code":       "ssq",
+		"expect":     issue,
+		"app_id":     p.appID,
+		"app_secret": p.secret,
+	}).Get(firstNonEmpty(p.opt.Endpoint, mxnzpIssueEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("mxnzp: request failed: %w", err)
+	}
+	return parseMxnzp(resp.Bytes())
+}
+
+type mxnzpResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		OpenCode string `json:"openCode"` // "01,05,12,18,25,31+09"
+		Code     string `json:"code"`
+		Expect   string `json:"expect"`
+		Name     string `json:"name"`
+		Time     string `json:"time"`
+	} `json:"data"`
+}
+
+func parseMxnzp(body []byte) (*store.Draw, error) {
+	var l mxnzpResp
+	if err := json.Unmarshal(body, &l); err != nil {
+		return nil, fmt.Errorf("mxnzp: decode: %w", err)
+	}
+	if l.Code != 1 {
+		return nil, fmt.Errorf("mxnzp: invalid response code: %d %s", l.Code, l.Msg)
+	}
+	if l.Data.Expect == "" {
+		return nil, nil
+	}
+	red, blue, err := store.ParseLineInts(l.Data.OpenCode)
+	if err != nil {
+		return nil, fmt.Errorf("mxnzp: %w", err)
+	}
+	return finish(store.Draw{
+		Issue:    l.Data.Expect,
+		DrawDate: l.Data.Time,
+		Reds:     red,
+		Blue:     blue,
+	}, sourceCrawler), nil
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"luck/backend/store"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"resty.dev/v3"
+)
+
+/* =============================== 接口 & 注册表 =============================== */
+
+var (
+	ErrUnknownProvider = errors.New("unknown_provider")
+	ErrNoCredentials   = errors.New("provider_credentials_missing")
+)
+
+// DrawProvider：最新一期开奖来源
+type DrawProvider interface {
+	Name() string
+	Latest(ctx context.Context) (*store.Draw, error)
+}
+
+// Options：构造 provider 的通用参数（来自 AppConfig）
+type Options struct {
+	APIKey   string        // 各家自有格式，见具体实现
+	Endpoint string        // HTTP 源：覆盖默认地址（便于指向录制的响应）；file 源：JSON 文件路径
+	Timeout  time.Duration // HTTP 超时；0 = 8 秒
+}
+
+func (o Options) client() *resty.Client {
+	t := o.Timeout
+	if t <= 0 {
+		t = 8 * time.Second
+	}
+	return resty.New().SetTimeout(t)
+}
+
+// IssueProvider：支持按期号查询的来源（用于回补缺期）
+type IssueProvider interface {
+	DrawProvider
+	ByIssue(ctx context.Context, issue string) (*store.Draw, error)
+}
+
+type Factory func(Options) (DrawProvider, error)
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Factory{}
+)
+
+// Register：注册 provider；名称大小写不敏感，可注册别名
+func Register(factory Factory, names ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, n := range names {
+		registry[strings.ToLower(n)] = factory
+	}
+}
+
+func New(name string, opt Options) (DrawProvider, error) {
+	mu.RLock()
+	f, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return f(opt)
+}
+
+// Names：已注册的名称（含别名），升序
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(registry))
+	for n := range registry {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+/* =============================== 小工具 =============================== */
+
+// 第三方接口拉取的开奖统一记为 crawler
+const sourceCrawler = "crawler"
+
+// 各 provider 解析结果的统一收尾：日期规范化、补 FetchedAt
+func finish(d store.Draw, source string) *store.Draw {
+	d.Issue = strings.TrimSpace(d.Issue)
+	d.DrawDate = store.NormalizeDate(d.DrawDate)
+	if d.Source == "" {
+		d.Source = source
+	}
+	if d.FetchedAt.IsZero() {
+		d.FetchedAt = time.Now()
+	}
+	return &d
+}
+
+func firstNonEmpty(ss ...string) string {
+	for _, s := range ss {
+		if strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
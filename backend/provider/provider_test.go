@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"luck/backend/store"
+)
+
+// 录制的接口响应放在 testdata/ 下，由本地 httptest 服务原样返回
+
+type fixtureCase struct {
+	name    string
+	fixture string
+	want    *store.Draw // nil 且 wantErr 为空：期望 (nil, nil)
+	wantErr string
+}
+
+func serveFixture(t *testing.T, name string, check func(*http.Request)) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func assertDraw(t *testing.T, got *store.Draw, err error, tc fixtureCase, source string) {
+	t.Helper()
+	if tc.wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Fatalf("err = %v, want containing %q", err, tc.wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tc.want == nil {
+		if got != nil {
+			t.Fatalf("got %+v, want nil", got)
+		}
+		return
+	}
+	if got == nil {
+		t.Fatal("got nil draw")
+	}
+	if got.Issue != tc.want.Issue || got.DrawDate != tc.want.DrawDate ||
+		!slices.Equal(got.Reds, tc.want.Reds) || got.Blue != tc.want.Blue {
+		t.Fatalf("got %s %s %v+%d, want %s %s %v+%d", got.Issue, got.DrawDate, got.Reds, got.Blue,
+			tc.want.Issue, tc.want.DrawDate, tc.want.Reds, tc.want.Blue)
+	}
+	if got.Source != source {
+		t.Errorf("source = %q, want %q", got.Source, source)
+	}
+	if got.FetchedAt.IsZero() {
+		t.Error("fetched_at not set")
+	}
+}
+
+func ctxT(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestMxnzpLatest(t *testing.T) {
+	cases := []fixtureCase{
+		{name: "ok", fixture: "mxnzp_latest.json", want: &store.Draw{
+			Issue: "2024098", DrawDate: "2024-08-25", Reds: []int{3, 9, 14, 20, 27, 31}, Blue: 12}},
+		{name: "error code", fixture: "mxnzp_error.json", wantErr: "invalid response code: 0"},
+		{name: "malformed", fixture: "malformed.json", wantErr: "mxnzp: decode"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := serveFixture(t, tc.fixture, func(r *http.Request) {
+				q := r.URL.Query()
+				if q.Get("code") != "ssq" || q.Get("app_id") != "id" || q.Get("app_secret") != "secret" {
+					t.Errorf("unexpected query: %s", r.URL.RawQuery)
+				}
+			})
+			p, err := New("mxnzp", Options{APIKey: "id:secret", Endpoint: srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Latest(ctxT(t))
+			assertDraw(t, got, err, tc, sourceCrawler)
+		})
+	}
+}
+
+func TestMxnzpByIssue(t *testing.T) {
+	srv := serveFixture(t, "mxnzp_latest.json", func(r *http.Request) {
+		if r.URL.Query().Get("expect") != "2024098" {
+			t.Errorf("expect = %q", r.URL.Query().Get("expect"))
+		}
+	})
+	p, err := New("mxnzp", Options{APIKey: "id:secret", Endpoint: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.(IssueProvider).ByIssue(ctxT(t), "2024098")
+	assertDraw(t, got, err, fixtureCase{want: &store.Draw{
+		Issue: "2024098", DrawDate: "2024-08-25", Reds: []int{3, 9, 14, 20, 27, 31}, Blue: 12}}, sourceCrawler)
+}
+
+func TestMxnzpNoCredentials(t *testing.T) {
+	t.Setenv("MXNZP_APP_ID", "")
+	t.Setenv("MXNZP_APP_SECRET", "")
+	if _, err := New("mxnzp", Options{}); err == nil || !strings.Contains(err.Error(), ErrNoCredentials.Error()) {
+		t.Fatalf("err = %v, want ErrNoCredentials", err)
+	}
+}
+
+func TestJisuLatest(t *testing.T) {
+	cases := []fixtureCase{
+		{name: "ok", fixture: "jisu_latest.json", want: &store.Draw{
+			Issue: "2024098", DrawDate: "2024-08-25", Reds: []int{3, 9, 14, 20, 27, 31}, Blue: 12}},
+		{name: "string status", fixture: "jisu_latest_str_status.json", want: &store.Draw{
+			Issue: "2024097", DrawDate: "2024-08-22", Reds: []int{1, 5, 12, 18, 25, 33}, Blue: 7}},
+		{name: "error status", fixture: "jisu_error.json", wantErr: "invalid response status: 101"},
+		{name: "malformed", fixture: "malformed.json", wantErr: "jisu: decode"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := serveFixture(t, tc.fixture, func(r *http.Request) {
+				if r.URL.Query().Get("appkey") != "k" {
+					t.Errorf("unexpected query: %s", r.URL.RawQuery)
+				}
+			})
+			p, err := New("jisu", Options{APIKey: "k", Endpoint: srv.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Latest(ctxT(t))
+			assertDraw(t, got, err, tc, sourceCrawler)
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	cases := []struct {
+		fixtureCase
+		issue string
+	}{
+		{fixtureCase: fixtureCase{name: "latest", fixture: "draws.json", want: &store.Draw{
+			Issue: "2024098", DrawDate: "2024-08-25", Reds: []int{3, 9, 14, 20, 27, 31}, Blue: 12}}},
+		{fixtureCase: fixtureCase{name: "by issue", fixture: "draws.json", want: &store.Draw{
+			Issue: "2024096", DrawDate: "2024-08-20", Reds: []int{2, 8, 11, 19, 24, 30}, Blue: 5}},
+			issue: "2024096"},
+		{fixtureCase: fixtureCase{name: "unknown issue", fixture: "draws.json"}, issue: "2099001"},
+		{fixtureCase: fixtureCase{name: "malformed", fixture: "malformed.json", wantErr: "file: decode"}},
+		{fixtureCase: fixtureCase{name: "missing file", fixture: "no_such_file.json", wantErr: "file:"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := New("file", Options{Endpoint: filepath.Join("testdata", tc.fixture)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got *store.Draw
+			if tc.issue == "" {
+				got, err = p.Latest(ctxT(t))
+			} else {
+				got, err = p.(IssueProvider).ByIssue(ctxT(t), tc.issue)
+			}
+			assertDraw(t, got, err, tc.fixtureCase, "file")
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"luck/backend/store"
+	"sync"
+)
+
+/* =============================== 多源交叉校验 =============================== */
+
+var ErrNoQuorum = errors.New("no_quorum")
+
+// Vote：单个 provider 的返回
+type Vote struct {
+	Provider string      `json:"provider"`
+	Draw     *store.Draw `json:"draw,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// Conflict：与采纳结果不一致（或未达成法定数时全部）的返回
+type Conflict struct {
+	Issue    string     `json:"issue"`
+	Provider string     `json:"provider"`
+	Draw     store.Draw `json:"draw"`
+	Reason   string     `json:"reason"` // disagree | no_quorum
+}
+
+type QuorumResult struct {
+	Draw      *store.Draw `json:"draw,omitempty"` // 采纳的结果；未达成时为 nil
+	Agreed    int         `json:"agreed"`         // 与采纳结果一致的源数
+	Quorum    int         `json:"quorum"`
+	Votes     []Vote      `json:"votes"`
+	Conflicts []Conflict  `json:"conflicts,omitempty"`
+}
+
+// Agree：并发请求全部 provider，内容（期号+日期+号码）完全一致的源数 >= quorum 时采纳。
+// 落后一期的源不算分歧；同一期号内容不同才记为冲突。quorum <= 0 视为 1。
+func Agree(ctx context.Context, ps []DrawProvider, quorum int) (*QuorumResult, error) {
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("no providers")
+	}
+	quorum = max(1, quorum)
+	out := &QuorumResult{Quorum: quorum, Votes: make([]Vote, len(ps))}
+
+	var wg sync.WaitGroup
+	for i, p := range ps {
+		wg.Add(1)
+		go func(i int, p DrawProvider) {
+			defer wg.Done()
+			v := Vote{Provider: p.Name()}
+			d, err := p.Latest(ctx)
+			switch {
+			case err != nil:
+				v.Error = err.Error()
+			case d == nil:
+				v.Error = "latest draw not found"
+			default:
+				v.Draw = d
+			}
+			out.Votes[i] = v
+		}(i, p)
+	}
+	wg.Wait()
+
+	// 按内容分组；同票数时取期号较新的一组
+	groups := map[string][]int{}
+	var best string
+	for i, v := range out.Votes {
+		if v.Draw == nil {
+			continue
+		}
+		k := drawKey(*v.Draw)
+		groups[k] = append(groups[k], i)
+		if best == "" || len(groups[k]) > len(groups[best]) ||
+			(len(groups[k]) == len(groups[best]) && v.Draw.Issue > out.Votes[groups[best][0]].Draw.Issue) {
+			best = k
+		}
+	}
+	if best != "" && len(groups[best]) >= quorum {
+		out.Draw = out.Votes[groups[best][0]].Draw
+		out.Agreed = len(groups[best])
+	}
+
+	for _, v := range out.Votes {
+		if v.Draw == nil {
+			continue
+		}
+		switch {
+		case out.Draw == nil:
+			out.Conflicts = append(out.Conflicts, Conflict{Issue: v.Draw.Issue, Provider: v.Provider, Draw: *v.Draw, Reason: "no_quorum"})
+		case v.Draw.Issue == out.Draw.Issue && drawKey(*v.Draw) != best:
+			out.Conflicts = append(out.Conflicts, Conflict{Issue: v.Draw.Issue, Provider: v.Provider, Draw: *v.Draw, Reason: "disagree"})
+		}
+	}
+	if out.Draw == nil {
+		return out, fmt.Errorf("%w: best agreement %d/%d", ErrNoQuorum, len(groups[best]), quorum)
+	}
+	return out, nil
+}
+
+func drawKey(d store.Draw) string {
+	return fmt.Sprintf("%s|%s|%v|%d", d.Issue, d.DrawDate, d.Reds, d.Blue)
+}
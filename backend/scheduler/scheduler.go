@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"luck/backend/store"
+	"sync"
+	"time"
+)
+
+/* =============================== 开奖日历 =============================== */
+
+// 双色球：每周二、四、日 21:15（北京时间）开奖
+var (
+	CST       = time.FixedZone("CST", 8*3600)
+	DrawDays  = []time.Weekday{time.Tuesday, time.Thursday, time.Sunday}
+	DrawClock = 21*time.Hour + 15*time.Minute
+)
+
+// NextDraw：t 之后（不含 t）最近的一次开奖时间
+func NextDraw(t time.Time) time.Time {
+	t = t.In(CST)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, CST)
+	for i := 0; i < 8; i++ {
+		d := day.AddDate(0, 0, i)
+		at := d.Add(DrawClock)
+		if isDrawDay(d.Weekday()) && at.After(t) {
+			return at
+		}
+	}
+	return time.Time{} // 不可达
+}
+
+func isDrawDay(w time.Weekday) bool {
+	for _, d := range DrawDays {
+		if d == w {
+			return true
+		}
+	}
+	return false
+}
+
+/* =============================== 调度 =============================== */
+
+// Options：开奖后 Delay 开始轮询；失败或结果未更新时按 Backoff 翻倍重试（上限 MaxBackoff），
+// 超过 Window 仍未拿到则放弃本期，转向下一期。
+type Options struct {
+	Delay      time.Duration
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Window     time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Delay:      15 * time.Minute,
+		Backoff:    time.Minute,
+		MaxBackoff: 30 * time.Minute,
+		Window:     15 * time.Hour,
+	}
+}
+
+// PollFunc：拉取并入库最新一期，返回拉到的开奖（可为 nil）
+type PollFunc func(ctx context.Context) (*store.Draw, error)
+
+type Status struct {
+	Running     bool      `json:"running"`
+	Target      string    `json:"target,omitempty"` // 正在等待的开奖日期（YYYY-MM-DD）
+	NextRun     time.Time `json:"next_run"`
+	LastRun     time.Time `json:"last_run,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastIssue   string    `json:"last_issue,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	Attempts    int       `json:"attempts"` // 本期已尝试次数
+	Skipped     string    `json:"skipped,omitempty"`
+}
+
+// 调用方按配置 Start/Stop；enabled 只兜住停止前已排上的那次轮询
+type Scheduler struct {
+	poll    PollFunc
+	opt     Options
+	enabled func() bool // 每次轮询前检查；返回 false 时本次跳过（仍按退避重试）
+
+	mu     sync.Mutex
+	status Status
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(poll PollFunc, opt Options, enabled func() bool) *Scheduler {
+	if enabled == nil {
+		enabled = func() bool { return true }
+	}
+	return &Scheduler{poll: poll, opt: opt, enabled: enabled}
+}
+
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel, s.done = cancel, make(chan struct{})
+	s.status.Running = true
+	go s.loop(ctx)
+}
+
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	defer func() {
+		s.mu.Lock()
+		s.status.Running, s.status.Target, s.status.NextRun = false, "", time.Time{}
+		s.mu.Unlock()
+		close(s.done)
+	}()
+
+	// 从仍在轮询窗口内的最近一次开奖开始（启动时可补拉）
+	draw := NextDraw(time.Now().Add(-s.opt.Delay - s.opt.Window))
+	for ctx.Err() == nil {
+		target := draw.Format("2006-01-02")
+		deadline := draw.Add(s.opt.Delay + s.opt.Window)
+		if !time.Now().Before(deadline) {
+			draw = NextDraw(draw)
+			continue
+		}
+		at := draw.Add(s.opt.Delay)
+		backoff := s.opt.Backoff
+		s.update(func(st *Status) { st.Target, st.Attempts, st.NextRun = target, 0, at })
+
+		for {
+			if !sleepUntil(ctx, at) {
+				return
+			}
+			if s.runOnce(ctx, target) {
+				break
+			}
+			at = time.Now().Add(backoff)
+			backoff = min(backoff*2, s.opt.MaxBackoff)
+			if !at.Before(deadline) {
+				log.Printf("scheduler: give up draw %s after %d attempts", target, s.Status().Attempts)
+				s.update(func(st *Status) { st.Skipped = target })
+				break
+			}
+			s.update(func(st *Status) { st.NextRun = at })
+		}
+		draw = NextDraw(draw)
+	}
+}
+
+// 返回 true 表示已拿到目标日期（或更新）的开奖
+func (s *Scheduler) runOnce(ctx context.Context, target string) bool {
+	now := time.Now()
+	s.update(func(st *Status) { st.LastRun = now; st.Attempts++ })
+	if !s.enabled() {
+		s.update(func(st *Status) { st.LastError = "api source disabled" })
+		return false
+	}
+	d, err := s.poll(ctx)
+	if err != nil {
+		s.update(func(st *Status) { st.LastError = err.Error() })
+		return false
+	}
+	if d == nil || d.DrawDate < target {
+		s.update(func(st *Status) { st.LastError = "draw for " + target + " not published yet" })
+		return false
+	}
+	s.update(func(st *Status) {
+		st.LastSuccess, st.LastIssue, st.LastError = now, d.Issue, ""
+	})
+	return true
+}
+
+func (s *Scheduler) update(fn func(*Status)) {
+	s.mu.Lock()
+	fn(&s.status)
+	s.mu.Unlock()
+}
+
+func sleepUntil(ctx context.Context, at time.Time) bool {
+	d := time.Until(at)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
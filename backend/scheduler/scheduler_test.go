@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"luck/backend/store"
+)
+
+func TestNextDraw(t *testing.T) {
+	cst := func(y int, m time.Month, d, hh, mm int) time.Time { return time.Date(y, m, d, hh, mm, 0, 0, CST) }
+	utc := func(y int, m time.Month, d, hh, mm int) time.Time { return time.Date(y, m, d, hh, mm, 0, 0, time.UTC) }
+	est := time.FixedZone("EST", -5*3600)
+
+	cases := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"monday → tuesday", cst(2024, 1, 1, 10, 0), cst(2024, 1, 2, 21, 15)},
+		{"draw day before the draw", cst(2024, 1, 2, 21, 14), cst(2024, 1, 2, 21, 15)},
+		{"exactly at the draw is excluded", cst(2024, 1, 2, 21, 15), cst(2024, 1, 4, 21, 15)},
+		{"thursday after the draw → sunday", cst(2024, 1, 4, 23, 0), cst(2024, 1, 7, 21, 15)},
+		{"saturday → sunday", cst(2024, 1, 6, 23, 59), cst(2024, 1, 7, 21, 15)},
+		{"sunday after the draw → next week's tuesday", cst(2024, 1, 7, 22, 0), cst(2024, 1, 9, 21, 15)},
+		{"year boundary", cst(2024, 12, 31, 22, 0), cst(2025, 1, 2, 21, 15)},
+		// 北京时间已是次日：按 CST 的日期判断
+		{"utc monday evening is tuesday in CST", utc(2024, 1, 1, 17, 0), cst(2024, 1, 2, 21, 15)},
+		{"utc tuesday evening is wednesday in CST", utc(2024, 1, 2, 20, 0), cst(2024, 1, 4, 21, 15)},
+		{"utc one minute before the draw", utc(2024, 1, 7, 13, 14), cst(2024, 1, 7, 21, 15)},
+		{"utc exactly at the draw", utc(2024, 1, 7, 13, 15), cst(2024, 1, 9, 21, 15)},
+		{"western zone, still saturday locally", time.Date(2024, 1, 6, 9, 0, 0, 0, est), cst(2024, 1, 7, 21, 15)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NextDraw(tc.in)
+			if !got.Equal(tc.want) || got.Location() != CST {
+				t.Fatalf("NextDraw(%s) = %s, want %s", tc.in, got, tc.want)
+			}
+		})
+	}
+
+	// 连续调用覆盖一整周：依次为二、四、日
+	at := cst(2024, 1, 1, 0, 0)
+	var days []time.Weekday
+	for range 6 {
+		at = NextDraw(at)
+		days = append(days, at.Weekday())
+	}
+	want := []time.Weekday{time.Tuesday, time.Thursday, time.Sunday, time.Tuesday, time.Thursday, time.Sunday}
+	for i := range want {
+		if days[i] != want[i] {
+			t.Fatalf("weekdays = %v, want %v", days, want)
+		}
+	}
+}
+
+// Start/Stop 可重复调用；停止后状态不再显示下一次运行
+func TestStartStop(t *testing.T) {
+	polled := make(chan struct{}, 1)
+	poll := func(ctx context.Context) (*store.Draw, error) {
+		polled <- struct{}{}
+		return nil, nil
+	}
+	// 窗口极短：启动时没有可补拉的开奖，只排上下一次
+	s := New(poll, Options{Backoff: time.Minute, MaxBackoff: time.Minute, Window: time.Nanosecond}, nil)
+
+	s.Stop() // 未启动
+	s.Start()
+	s.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Status().NextRun.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	st := s.Status()
+	if !st.Running || !st.NextRun.After(time.Now()) || st.Target != st.NextRun.Format("2006-01-02") {
+		t.Fatalf("status after start = %+v", st)
+	}
+
+	s.Stop()
+	s.Stop()
+	if st = s.Status(); st.Running || !st.NextRun.IsZero() || st.Target != "" {
+		t.Fatalf("status after stop = %+v", st)
+	}
+	select {
+	case <-polled:
+		t.Fatal("polled before the next draw")
+	default:
+	}
+}
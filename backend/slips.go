@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"luck/backend/generator"
+	"luck/backend/prize"
+	"luck/backend/store"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/* ===================== 购买记录（slips）CRUD ===================== */
+
+type slipRequest struct {
+	Name    string         `json:"name"`
+	Issue   string         `json:"issue"`
+	Tickets []store.Ticket `json:"tickets"`
+}
+
+func listSlipsHandler(c *gin.Context) {
+	list, err := st.ListSlips(c.Query("issue"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func getSlipHandler(c *gin.Context) {
+	id, ok := slipIDParam(c)
+	if !ok {
+		return
+	}
+	sl, err := st.GetSlip(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sl == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrSlipNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sl)
+}
+
+// 手工录入（例如线下购买的号码）
+func createSlipHandler(c *gin.Context) {
+	var in slipRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(in.Tickets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tickets required"})
+		return
+	}
+	if err := validateTickets(in.Tickets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sl := store.Slip{Name: in.Name, Issue: in.Issue, Tickets: in.Tickets}
+	if sl.Issue == "" {
+		sl.Issue = targetIssue()
+	}
+	if strings.TrimSpace(sl.Name) == "" {
+		sl.Name = defaultSlipName(sl.Issue)
+	}
+	if err := st.CreateSlip(&sl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sl)
+}
+
+// 更新名称/期号；带 tickets 时整体替换
+func updateSlipHandler(c *gin.Context) {
+	id, ok := slipIDParam(c)
+	if !ok {
+		return
+	}
+	old, err := st.GetSlip(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if old == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrSlipNotFound.Error()})
+		return
+	}
+	var in slipRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 未给 tickets 表示不改号码；显式给出空列表会清空全部注，拒绝
+	if in.Tickets != nil && len(in.Tickets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tickets required"})
+		return
+	}
+	if err := validateTickets(in.Tickets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	upd := store.Slip{ID: id, Name: old.Name, Issue: old.Issue, Tickets: in.Tickets}
+	if strings.TrimSpace(in.Name) != "" {
+		upd.Name = in.Name
+	}
+	if strings.TrimSpace(in.Issue) != "" {
+		upd.Issue = in.Issue
+	}
+	if err := st.UpdateSlip(upd); err != nil {
+		if errors.Is(err, store.ErrSlipNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sl, err := st.GetSlip(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sl)
+}
+
+func deleteSlipHandler(c *gin.Context) {
+	id, ok := slipIDParam(c)
+	if !ok {
+		return
+	}
+	if err := st.DeleteSlip(id); err != nil {
+		if errors.Is(err, store.ErrSlipNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+/* ===================== 生成结果落库 ===================== */
+
+// saveGeneratedSlip：把本次生成的号码连同生效配置记为一条 slip
+func saveGeneratedSlip(name, issue string, use generator.Config, gen []generator.Ticket) (*store.Slip, error) {
+	if issue == "" {
+		issue = targetIssue()
+	}
+	if strings.TrimSpace(name) == "" {
+		name = defaultSlipName(issue)
+	}
+	raw, err := json.Marshal(use)
+	if err != nil {
+		return nil, err
+	}
+	tickets := make([]store.Ticket, 0, len(gen))
+	for _, t := range gen {
+		tickets = append(tickets, t.Store())
+	}
+	sl := store.Slip{Name: name, Issue: issue, Config: raw, Seed: use.Seed, Tickets: tickets}
+	if err := st.CreateSlip(&sl); err != nil {
+		return nil, err
+	}
+	return &sl, nil
+}
+
+// POST /api/slips/:id/replay：用记录中的配置与种子重新生成（不落库），核对是否与记录一致。
+// 注意：生成会与当前历史去重，期间若新增开奖数据，结果可能不同。
+func replaySlipHandler(c *gin.Context) {
+	id, ok := slipIDParam(c)
+	if !ok {
+		return
+	}
+	sl, err := st.GetSlip(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sl == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrSlipNotFound.Error()})
+		return
+	}
+	if len(sl.Config) == 0 || sl.Seed == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "slip has no generator config/seed (manual entry?)"})
+		return
+	}
+	var use generator.Config
+	if err := json.Unmarshal(sl.Config, &use); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "bad stored config: " + err.Error()})
+		return
+	}
+	use.Seed = sl.Seed
+	tickets, _, err := generator.LuckTickets(use, st)
+	if err != nil {
+		c.JSON(generateErrorStatus(err), generateErrorBody(err))
+		return
+	}
+	replayed := make([]store.Ticket, 0, len(tickets))
+	for _, t := range tickets {
+		replayed = append(replayed, t.Store())
+	}
+	same, _ := json.Marshal(replayed)
+	orig, _ := json.Marshal(sl.Tickets)
+	c.JSON(http.StatusOK, gin.H{
+		"slip_id":   sl.ID,
+		"seed":      sl.Seed,
+		"identical": string(same) == string(orig),
+		"tickets":   replayed,
+	})
+}
+
+/* ===================== 工具函数 ===================== */
+
+// 按票型规则校验（单式/复式/胆拖）
+func validateTickets(in []store.Ticket) error {
+	for i, raw := range in {
+		t, err := generator.TicketFromStore(raw)
+		if err == nil {
+			err = t.Validate()
+		}
+		if err != nil {
+			return fmt.Errorf("ticket %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func slipIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid slip id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// 默认目标期号：库中最新一期的下一期
+func targetIssue() string {
+	latest, err := st.LatestDraw()
+	if err != nil || latest == nil {
+		return ""
+	}
+	return nextIssue(*latest, time.Now())
+}
+
+// 期号为 YYYYNNN：同年 +1；最新一期已是往年则从当年 001 起
+func nextIssue(latest store.Draw, now time.Time) string {
+	issue := strings.TrimSpace(latest.Issue)
+	if len(issue) != 7 {
+		return ""
+	}
+	year, err1 := strconv.Atoi(issue[:4])
+	seq, err2 := strconv.Atoi(issue[4:])
+	if err1 != nil || err2 != nil {
+		return ""
+	}
+	if now.Year() > year {
+		return fmt.Sprintf("%d001", now.Year())
+	}
+	return fmt.Sprintf("%04d%03d", year, seq+1)
+}
+
+func defaultSlipName(issue string) string {
+	ts := time.Now().Format("01-02 15:04:05")
+	if issue == "" {
+		return ts
+	}
+	return fmt.Sprintf("第%s期 %s", issue, ts)
+}
+
+/* ===================== 兑奖结果 & 每期盈亏 ===================== */
+
+type ticketWithResult struct {
+	store.Ticket
+	Bets   int                 `json:"bets"`
+	Result *store.TicketResult `json:"result,omitempty"`
+}
+
+// GET /api/slips/:id/result：未开奖返回 settled=false；已开奖但尚未兑奖（如开奖先于录入）则当场兑奖
+func slipResultHandler(c *gin.Context) {
+	id, ok := slipIDParam(c)
+	if !ok {
+		return
+	}
+	sl, err := st.GetSlip(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sl == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrSlipNotFound.Error()})
+		return
+	}
+	d, err := st.GetByIssue(sl.Issue)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var results []store.TicketResult
+	if d != nil {
+		if results, err = st.SlipResults(sl.ID); err == nil && len(results) != len(sl.Tickets) {
+			results, err = prize.ScoreSlip(st, *sl, *d)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	sum := prize.Summarize(sl.Issue, d, []store.Slip{*sl}, results)
+	tickets := make([]ticketWithResult, len(sl.Tickets))
+	for i, raw := range sl.Tickets {
+		tickets[i].Ticket = raw
+		if t, err := generator.TicketFromStore(raw); err == nil {
+			tickets[i].Bets = t.Bets()
+		}
+	}
+	for i := range results {
+		if r := &results[i]; r.Idx >= 0 && r.Idx < len(tickets) {
+			tickets[r.Idx].Result = r
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"slip_id":     sl.ID,
+		"name":        sl.Name,
+		"issue":       sl.Issue,
+		"settled":     d != nil,
+		"draw":        d,
+		"tickets":     tickets,
+		"bets":        sum.Bets,
+		"cost_yuan":   sum.CostYuan,
+		"prize_yuan":  sum.PrizeYuan,
+		"floating":    sum.Floating,
+		"tier_counts": sum.TierCounts,
+	})
+}
+
+// GET /api/winnings/:issue：某期全部 slip 的投入/中奖汇总
+func issueWinningsHandler(c *gin.Context) {
+	issue := strings.TrimSpace(c.Param("issue"))
+	slips, err := st.ListSlips(issue)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := st.GetByIssue(issue)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// 只读：按当前开奖号码现算，不改写已存的兑奖结果
+	var results []store.TicketResult
+	if d != nil {
+		for _, sl := range slips {
+			rs, err := prize.CheckSlip(sl, *d)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			results = append(results, rs...)
+		}
+	}
+	c.JSON(http.StatusOK, prize.Summarize(issue, d, slips, results))
+}
+
+// 开奖入库/更正后自动兑奖（在 main 中注册到 store）
+func settleOnDraw(d store.Draw, status string) {
+	n, err := prize.ScoreIssue(st, d)
+	if err != nil {
+		log.Printf("settle issue %s (%s) failed: %v", d.Issue, status, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("settled %d slip(s) for issue %s (%s)", n, d.Issue, status)
+	}
+}
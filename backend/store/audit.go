@@ -0,0 +1,191 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDrawExists   = errors.New("draw_exists")
+	ErrDrawNotFound = errors.New("draw_not_found")
+)
+
+/* ----------------------------- 手工维护 + 审计 ----------------------------- */
+
+// 谁、从哪里做的修改
+type AuditMeta struct {
+	Actor  string
+	Source string // 变更渠道，如 api
+	Remote string
+}
+
+type DrawAudit struct {
+	ID        int64     `json:"id"`
+	Issue     string    `json:"issue"`
+	Action    string    `json:"action"` // insert | update | delete
+	Old       *Draw     `json:"old,omitempty"`
+	New       *Draw     `json:"new,omitempty"`
+	Actor     string    `json:"actor"`
+	Source    string    `json:"source"`
+	Remote    string    `json:"remote,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InsertDraw：新增一期；期号已存在返回 ErrDrawExists
+func (s *Store) InsertDraw(d Draw, meta AuditMeta) (*Draw, error) {
+	return s.changeDraw("insert", d.Issue, &d, meta)
+}
+
+// UpdateDraw：更正一期（期号取 issue）；不存在返回 ErrDrawNotFound。内容未变时不写审计
+func (s *Store) UpdateDraw(issue string, d Draw, meta AuditMeta) (*Draw, error) {
+	d.Issue = issue
+	return s.changeDraw("update", issue, &d, meta)
+}
+
+// DeleteDraw：删除一期；返回被删除的旧值
+func (s *Store) DeleteDraw(issue string, meta AuditMeta) (*Draw, error) {
+	return s.changeDraw("delete", issue, nil, meta)
+}
+
+// 变更与审计记录在同一事务内；insert/update 提交后触发 DrawHook
+func (s *Store) changeDraw(action, issue string, in *Draw, meta AuditMeta) (out *Draw, err error) {
+	issue = strings.TrimSpace(issue)
+	if issue == "" {
+		return nil, fmt.Errorf("issue required")
+	}
+	var norm Draw
+	if in != nil {
+		if norm, err = normalizeDraw(*in); err != nil {
+			return nil, err
+		}
+		norm.Issue = issue
+		if norm.FetchedAt.IsZero() {
+			norm.FetchedAt = time.Now()
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	status := ""
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err == nil && status != "" {
+			s.fireDrawChanged(norm, status)
+		}
+	}()
+
+	prev, err := getByIssue(tx, issue)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case action == "insert" && prev != nil:
+		return nil, ErrDrawExists
+	case action != "insert" && prev == nil:
+		return nil, ErrDrawNotFound
+	}
+
+	ts := norm.FetchedAt.Format(time.RFC3339Nano)
+	switch action {
+	case "insert":
+		_, err = tx.Exec(insertDrawSQL, insertDrawArgs(issue, norm, norm.Source, ts)...)
+		status, out = "inserted", &norm
+	case "update":
+		if sameDraw(*prev, norm) && prev.Source == norm.Source {
+			return prev, nil
+		}
+		_, err = tx.Exec(updateDrawSQL, updateDrawArgs(norm, norm.Source, ts)...)
+		status, out = "updated", &norm
+	case "delete":
+		// 已兑奖结果依赖这期开奖，随之清除，避免继续展示过期的中奖
+		if _, err = tx.Exec(`DELETE FROM draws WHERE issue=?`, issue); err == nil {
+			_, err = tx.Exec(`DELETE FROM slip_results WHERE issue=?`, issue)
+		}
+		out = prev
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var newVal *Draw
+	if in != nil {
+		newVal = &norm
+	}
+	if err = insertAudit(tx, issue, action, prev, newVal, meta); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// insertAudit：与变更同一事务写入审计；无期号的行无法定位，不记录
+func insertAudit(ex execer, issue, action string, old, new *Draw, meta AuditMeta) error {
+	if issue == "" {
+		return nil
+	}
+	_, err := ex.Exec(`INSERT INTO draw_audit(issue, action, old_value, new_value, actor, source, remote, created_at)
+VALUES(?,?,?,?,?,?,?,?)`, issue, action, drawJSON(old), drawJSON(new),
+		meta.Actor, meta.Source, nullIfEmpty(meta.Remote), time.Now().Format(time.RFC3339Nano))
+	return err
+}
+
+func drawJSON(d *Draw) any {
+	if d == nil {
+		return nil
+	}
+	b, _ := json.Marshal(d)
+	return string(b)
+}
+
+// ListDrawAudit：某期的变更记录，按时间倒序；limit<=0 不限
+func (s *Store) ListDrawAudit(issue string, limit int) ([]DrawAudit, error) {
+	q := `SELECT id, issue, action, old_value, new_value, actor, source, remote, created_at
+FROM draw_audit WHERE issue=? ORDER BY id DESC`
+	args := []any{strings.TrimSpace(issue)}
+	if limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []DrawAudit{}
+	for rows.Next() {
+		var a DrawAudit
+		var oldVal, newVal, remote *string
+		var created string
+		if err := rows.Scan(&a.ID, &a.Issue, &a.Action, &oldVal, &newVal, &a.Actor, &a.Source, &remote, &created); err != nil {
+			return nil, err
+		}
+		a.Old, a.New = decodeDraw(oldVal), decodeDraw(newVal)
+		if remote != nil {
+			a.Remote = *remote
+		}
+		if t, e := parseTimeFlexible(created); e == nil {
+			a.CreatedAt = t
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func decodeDraw(raw *string) *Draw {
+	if raw == nil {
+		return nil
+	}
+	var d Draw
+	if json.Unmarshal([]byte(*raw), &d) != nil {
+		return nil
+	}
+	return &d
+}
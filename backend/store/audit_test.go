@@ -0,0 +1,168 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func auditActions(t *testing.T, s *Store, issue string) []string {
+	t.Helper()
+	list, err := s.ListDrawAudit(issue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, a := range list {
+		out = append(out, a.Actor+"/"+a.Source+"/"+a.Action)
+	}
+	return out
+}
+
+// 录入 → 更正（含一次无变化的更正）→ 删除：每步一条审计，按时间倒序列出，删除时清掉该期兑奖结果
+func TestDrawAuditInsertUpdateDelete(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	meta := AuditMeta{Actor: "alice", Source: "api", Remote: "10.0.0.1"}
+	d := Draw{Issue: "2024001", DrawDate: "2024-01-02", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9}
+	if _, err := s.InsertDraw(d, meta); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.InsertDraw(d, meta); err != ErrDrawExists {
+		t.Fatalf("second insert: err = %v", err)
+	}
+	fixed := d
+	fixed.Blue = 10
+	if _, err := s.UpdateDraw(d.Issue, fixed, meta); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateDraw(d.Issue, fixed, meta); err != nil { // 内容未变，不写审计
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateDraw("2024999", fixed, meta); err != ErrDrawNotFound {
+		t.Fatalf("update missing: err = %v", err)
+	}
+
+	sl := Slip{Issue: d.Issue, Tickets: []Ticket{{Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 10}}}
+	if err := s.CreateSlip(&sl); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSlipResults(sl.ID, []TicketResult{{Issue: d.Issue, RedHits: 6, BlueHit: true, Tier: 1, Bets: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	prev, err := s.DeleteDraw(d.Issue, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev == nil || prev.Blue != 10 {
+		t.Fatalf("delete returned %+v", prev)
+	}
+	if rs, err := s.SlipResults(sl.ID); err != nil || len(rs) != 0 {
+		t.Fatalf("slip results after delete = %+v, %v", rs, err)
+	}
+
+	list, err := s.ListDrawAudit(d.Issue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, a := range list {
+		actions = append(actions, a.Action)
+		if a.Actor != "alice" || a.Source != "api" || a.Remote != "10.0.0.1" || a.CreatedAt.IsZero() {
+			t.Errorf("audit %d meta = %+v", a.ID, a)
+		}
+	}
+	if !slices.Equal(actions, []string{"delete", "update", "insert"}) {
+		t.Fatalf("actions = %v", actions)
+	}
+	del, upd, ins := list[0], list[1], list[2]
+	if ins.Old != nil || ins.New == nil || ins.New.Blue != 9 {
+		t.Errorf("insert old/new = %+v / %+v", ins.Old, ins.New)
+	}
+	if upd.Old == nil || upd.Old.Blue != 9 || upd.New == nil || upd.New.Blue != 10 {
+		t.Errorf("update old/new = %+v / %+v", upd.Old, upd.New)
+	}
+	if del.Old == nil || del.Old.Blue != 10 || del.New != nil {
+		t.Errorf("delete old/new = %+v / %+v", del.Old, del.New)
+	}
+
+	if got, err := s.ListDrawAudit(d.Issue, 1); err != nil || len(got) != 1 || got[0].Action != "delete" {
+		t.Fatalf("limit 1 = %+v, %v", got, err)
+	}
+	if got, err := s.ListDrawAudit("2024999", 0); err != nil || len(got) != 0 {
+		t.Fatalf("unknown issue = %+v, %v", got, err)
+	}
+}
+
+// 拉取对账、合并导入与替换导入写入的变化同样进审计
+func TestDrawAuditReconcileAndImport(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	d := Draw{Issue: "2024001", DrawDate: "2024-01-02", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9, Source: sourceCrawler}
+	for _, blue := range []int{9, 9, 10} { // inserted, noop, updated
+		d.Blue = blue
+		if _, _, err := s.ReconcileIssue(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := auditActions(t, s, d.Issue); !slices.Equal(got, []string{"system/reconcile/update", "system/reconcile/insert"}) {
+		t.Fatalf("reconcile audit = %v", got)
+	}
+
+	// 合并：新期插入、manual 行更正；crawler 行保留不写审计
+	if err := s.UpsertDrawByIssue(Draw{Issue: "2024002", DrawDate: "2024-01-04", Reds: []int{2, 6, 13, 19, 26, 32}, Blue: 10, Source: "manual"}); err != nil {
+		t.Fatal(err)
+	}
+	merge := writeFile(t, "merge.json", `[
+{"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,33],"blue":9},
+{"issue":"2024002","draw_date":"2024-01-04","reds":[2,6,13,19,26,33],"blue":10},
+{"issue":"2024003","draw_date":"2024-01-07","reds":[3,7,14,20,27,33],"blue":11}]`)
+	meta := AuditMeta{Actor: "bob", Source: "upload"}
+	if _, err := s.ImportFile(merge, ImportOptions{Merge: true, Audit: meta}); err != nil {
+		t.Fatal(err)
+	}
+	if got := auditActions(t, s, "2024001"); len(got) != 2 {
+		t.Fatalf("kept crawler row got audit %v", got)
+	}
+	if got := auditActions(t, s, "2024002"); !slices.Equal(got, []string{"bob/upload/update"}) {
+		t.Fatalf("merge update audit = %v", got)
+	}
+	if got := auditActions(t, s, "2024003"); !slices.Equal(got, []string{"bob/upload/insert"}) {
+		t.Fatalf("merge insert audit = %v", got)
+	}
+
+	// 替换：未变的期不记，缺失的期记删除并清掉兑奖结果；未给 Audit 时记为 system/import
+	sl := Slip{Issue: "2024003", Tickets: []Ticket{{Reds: []int{3, 7, 14, 20, 27, 33}, Blue: 11}}}
+	if err := s.CreateSlip(&sl); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSlipResults(sl.ID, []TicketResult{{Issue: "2024003", RedHits: 6, BlueHit: true, Tier: 1, Bets: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	replace := writeFile(t, "replace.json", `[
+{"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,31],"blue":10,"source":"crawler"},
+{"issue":"2024002","draw_date":"2024-01-04","reds":[2,6,13,19,26,32],"blue":10}]`)
+	if _, err := s.ImportFile(replace, ImportOptions{Replace: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := auditActions(t, s, "2024001"); len(got) != 2 {
+		t.Fatalf("unchanged row got audit %v", got)
+	}
+	if got := auditActions(t, s, "2024002"); !slices.Equal(got, []string{"system/import/update", "bob/upload/update"}) {
+		t.Fatalf("replace update audit = %v", got)
+	}
+	if got := auditActions(t, s, "2024003"); !slices.Equal(got, []string{"system/import/delete", "bob/upload/insert"}) {
+		t.Fatalf("replace delete audit = %v", got)
+	}
+	if rs, err := s.SlipResults(sl.ID); err != nil || len(rs) != 0 {
+		t.Fatalf("slip results after replace = %+v, %v", rs, err)
+	}
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 上传的文件不是可用的本程序数据库（非 SQLite、损坏或缺少 draws 表）
+var ErrInvalidBackup = errors.New("invalid backup")
+
+/* ----------------------------- 备份 / 恢复 ----------------------------- */
+
+// Backup：VACUUM INTO 生成一致性快照（WAL 下运行中也安全），写到 w 后删除临时文件
+func (s *Store) Backup(w io.Writer) error {
+	f, err := os.CreateTemp(filepath.Dir(s.dbPath), "backup-*.db")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_ = f.Close()
+	_ = os.Remove(tmp) // VACUUM INTO 要求目标文件不存在
+	defer os.Remove(tmp)
+
+	if _, err := s.db.Exec(`VACUUM INTO ?`, tmp); err != nil {
+		return err
+	}
+	src, err := os.Open(tmp)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(w, src)
+	return err
+}
+
+type RestoreReport struct {
+	FromVersion int            `json:"from_version"` // 上传库的 schema 版本（0 = 版本管理之前）
+	Version     int            `json:"version"`      // 恢复后的版本
+	Tables      map[string]int `json:"tables"`       // 各表恢复的行数
+}
+
+// Restore：校验上传的库（完整性、必须有 draws 表、版本不高于本程序），
+// 先在临时文件上迁移到当前版本，再在一个事务里整体替换各表数据。
+// 不替换库文件本身，运行中的查询不受影响；任一步失败都不会改动现有数据。
+func (s *Store) Restore(r io.Reader) (*RestoreReport, error) {
+	f, err := os.CreateTemp(filepath.Dir(s.dbPath), "restore-*.db")
+	if err != nil {
+		return nil, err
+	}
+	tmp := f.Name()
+	defer func() {
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			_ = os.Remove(tmp + suffix)
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	rep, err := prepareRestore(tmp)
+	if err != nil {
+		return nil, err
+	}
+	if rep.Tables, err = s.copyFrom(tmp); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// 校验并把上传的库迁移到当前 schema
+func prepareRestore(path string) (*RestoreReport, error) {
+	db, err := sql.Open(driverName, dsn(path, connPragmas))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRow(`PRAGMA quick_check`).Scan(&check); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if check != "ok" {
+		return nil, fmt.Errorf("%w: quick_check: %s", ErrInvalidBackup, check)
+	}
+	if ok, err := hasTable(db, "draws"); err != nil || !ok {
+		return nil, fmt.Errorf("%w: no draws table", ErrInvalidBackup)
+	}
+	rep := &RestoreReport{}
+	if ok, _ := hasTable(db, "schema_migrations"); ok {
+		if rep.FromVersion, err = schemaVersion(db); err != nil {
+			return nil, err
+		}
+	}
+	if latest := SchemaVersion(); rep.FromVersion > latest {
+		return nil, fmt.Errorf("%w: backup v%d, binary v%d", ErrSchemaTooNew, rep.FromVersion, latest)
+	}
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+	rep.Version = SchemaVersion()
+	return rep, nil
+}
+
+func hasTable(q rowQuerier, name string) (bool, error) {
+	var n int
+	err := q.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, name).Scan(&n)
+	return n > 0, err
+}
+
+// 在同一连接上 ATTACH 源库，事务内逐表 DELETE + INSERT SELECT（列名显式列出，不依赖列顺序）
+func (s *Store) copyFrom(path string) (counts map[string]int, err error) {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 整体替换时父子表先后顺序无关紧要；外键只能在事务外开关
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys=OFF`); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, `PRAGMA foreign_keys=ON`)
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS src`, path); err != nil {
+		return nil, err
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE src`)
+
+	tables, err := userTables(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	counts = map[string]int{}
+	for _, t := range tables {
+		cols, err := tableColumns(tx, t)
+		if err != nil {
+			return nil, err
+		}
+		list := strings.Join(cols, ", ")
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM main.%q`, t)); err != nil {
+			return nil, err
+		}
+		res, err := tx.Exec(fmt.Sprintf(`INSERT INTO main.%q(%s) SELECT %s FROM src.%q`, t, list, list, t))
+		if err != nil {
+			return nil, fmt.Errorf("restore %s: %w", t, err)
+		}
+		n, _ := res.RowsAffected()
+		counts[t] = int(n)
+	}
+	// AUTOINCREMENT 计数器随数据一起恢复
+	if _, err := tx.Exec(`DELETE FROM main.sqlite_sequence`); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`INSERT INTO main.sqlite_sequence(name, seq) SELECT name, seq FROM src.sqlite_sequence`); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// 业务表：不含 sqlite_* 与 schema_migrations（两边迁移后版本一致）
+func userTables(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT name FROM main.sqlite_master
+WHERE type='table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations' ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		out = append(out, name)
+	}
+	return out, rows.Err()
+}
+
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?, 'main')`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, fmt.Sprintf("%q", name))
+	}
+	return cols, rows.Err()
+}
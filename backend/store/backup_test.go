@@ -0,0 +1,188 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func slipSeq(t *testing.T, s *Store) int64 {
+	t.Helper()
+	var seq int64
+	if err := s.db.QueryRow(`SELECT seq FROM sqlite_sequence WHERE name='slips'`).Scan(&seq); err != nil {
+		t.Fatal(err)
+	}
+	return seq
+}
+
+// 备份 → 改动 → 恢复：数据、审计与 AUTOINCREMENT 计数器回到备份时的状态
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	meta := AuditMeta{Actor: "alice", Source: "api"}
+	for i, issue := range []string{"2024001", "2024002"} {
+		d := Draw{Issue: issue, DrawDate: "2024-01-02", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9 + i}
+		if _, err := s.InsertDraw(d, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		sl := Slip{Name: name, Issue: "2024003", Tickets: []Ticket{{Reds: []int{1, 2, 3, 4, 5, 6}, Blue: 1}}}
+		if err := s.CreateSlip(&sl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 删掉最大的 id：计数器仍是 3，恢复后新建的 slip 不会复用 3
+	if err := s.DeleteSlip(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutSetting("note", "before"); err != nil {
+		t.Fatal(err)
+	}
+	wantDraws, _ := s.ListRecentDraws(0)
+	wantSlips, _ := s.ListSlips("")
+
+	var buf bytes.Buffer
+	if err := s.Backup(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("SQLite format 3\x00")) {
+		t.Fatal("backup is not a sqlite file")
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "backup-*")); len(left) != 0 {
+		t.Fatalf("temp files left behind: %v", left)
+	}
+
+	// 改动：删一期、再建 slip、改设置
+	if _, err := s.DeleteDraw("2024002", meta); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := s.CreateSlip(&Slip{Name: "later", Tickets: []Ticket{{Reds: []int{1, 2, 3, 4, 5, 6}, Blue: 1}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.PutSetting("note", "after"); err != nil {
+		t.Fatal(err)
+	}
+	if seq := slipSeq(t, s); seq != 5 {
+		t.Fatalf("seq before restore = %d", seq)
+	}
+
+	rep, err := s.Restore(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.FromVersion != SchemaVersion() || rep.Version != SchemaVersion() {
+		t.Fatalf("versions = %d -> %d", rep.FromVersion, rep.Version)
+	}
+	if rep.Tables["draws"] != 2 || rep.Tables["slips"] != 2 || rep.Tables["draw_audit"] != 2 {
+		t.Fatalf("tables = %v", rep.Tables)
+	}
+	if _, ok := rep.Tables["schema_migrations"]; ok {
+		t.Fatal("schema_migrations was copied")
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "restore-*")); len(left) != 0 {
+		t.Fatalf("temp files left behind: %v", left)
+	}
+
+	gotDraws, _ := s.ListRecentDraws(0)
+	gotSlips, _ := s.ListSlips("")
+	if !reflect.DeepEqual(gotDraws, wantDraws) || !reflect.DeepEqual(gotSlips, wantSlips) {
+		t.Fatalf("restored draws/slips differ:\n%+v\n%+v", gotDraws, gotSlips)
+	}
+	if raw, err := s.GetSetting("note"); err != nil || string(raw) != `"before"` {
+		t.Fatalf("setting = %s, %v", raw, err)
+	}
+	if got := auditActions(t, s, "2024002"); len(got) != 1 {
+		t.Fatalf("audit after restore = %v", got)
+	}
+	if seq := slipSeq(t, s); seq != 3 {
+		t.Fatalf("seq after restore = %d, want 3", seq)
+	}
+	next := Slip{Name: "next", Tickets: []Ticket{{Reds: []int{1, 2, 3, 4, 5, 6}, Blue: 1}}}
+	if err := s.CreateSlip(&next); err != nil || next.ID != 4 {
+		t.Fatalf("next slip id = %d, %v", next.ID, err)
+	}
+	var fk int
+	if err := s.db.QueryRow(`PRAGMA foreign_keys`).Scan(&fk); err != nil || fk != 1 {
+		t.Fatalf("foreign_keys = %d, %v", fk, err)
+	}
+}
+
+// 上传的库版本更高 / 不是 SQLite / 缺 draws 表：拒绝且不改动现有数据
+func TestRestoreRejects(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	d := Draw{Issue: "2024001", DrawDate: "2024-01-02", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9}
+	if err := s.UpsertDrawByIssue(d); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := s.Backup(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// 在备份上追加一条未来版本的迁移记录
+	newer := filepath.Join(t.TempDir(), "newer.db")
+	if err := os.WriteFile(newer, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driverName, dsn(newer, connPragmas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM draws`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations(version, name, applied_at) VALUES(?,?,?)`,
+		SchemaVersion()+1, "future", time.Now().Format(time.RFC3339)); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	raw, err := os.ReadFile(newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Restore(bytes.NewReader(raw)); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("newer schema: err = %v", err)
+	}
+
+	if _, err := s.Restore(strings.NewReader("definitely not sqlite")); !errors.Is(err, ErrInvalidBackup) {
+		t.Fatalf("garbage: err = %v", err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.db")
+	db, err = sql.Open(driverName, dsn(empty, connPragmas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE other(x)`); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	if raw, err = os.ReadFile(empty); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Restore(bytes.NewReader(raw)); !errors.Is(err, ErrInvalidBackup) {
+		t.Fatalf("no draws table: err = %v", err)
+	}
+
+	if got, err := s.GetByIssue("2024001"); err != nil || got == nil {
+		t.Fatalf("data changed after rejected restores: %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 多源交叉校验时与采纳结果不一致的返回（见 provider.Agree）
+type DrawConflict struct {
+	ID        int64     `json:"id"`
+	Issue     string    `json:"issue"`
+	Provider  string    `json:"provider"`
+	DrawDate  string    `json:"draw_date"`
+	Reds      []int     `json:"reds"`
+	Blue      int       `json:"blue"`
+	Reason    string    `json:"reason"`             // disagree | no_quorum
+	Accepted  *Draw     `json:"accepted,omitempty"` // 采纳的结果；未达成法定数时为空
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RecordConflicts：同一源对同一期的同一组号码已记录过则忽略（定时拉取会反复遇到同一分歧）
+func (s *Store) RecordConflicts(list []DrawConflict) (err error) {
+	if len(list) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	now := time.Now().Format(time.RFC3339Nano)
+	for _, c := range list {
+		sorted := append([]int(nil), c.Reds...)
+		sort.Ints(sorted) // 唯一索引按 JSON 文本比较，统一为升序
+		reds, _ := json.Marshal(sorted)
+		var accepted any
+		if c.Accepted != nil {
+			b, _ := json.Marshal(c.Accepted)
+			accepted = string(b)
+		}
+		if _, err = tx.Exec(`
+INSERT OR IGNORE INTO draw_conflicts(issue, provider, draw_date, reds, blue, reason, accepted, created_at)
+VALUES(?,?,?,?,?,?,?,?)
+`, c.Issue, c.Provider, c.DrawDate, string(reds), c.Blue, c.Reason, accepted, now); err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	return err
+}
+
+// ListConflicts：按时间倒序；issue 为空表示全部，limit<=0 不限
+func (s *Store) ListConflicts(issue string, limit int) ([]DrawConflict, error) {
+	q := `SELECT id, issue, provider, draw_date, reds, blue, reason, accepted, created_at FROM draw_conflicts`
+	var args []any
+	if issue = strings.TrimSpace(issue); issue != "" {
+		q += ` WHERE issue=?`
+		args = append(args, issue)
+	}
+	q += ` ORDER BY id DESC`
+	if limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []DrawConflict{}
+	for rows.Next() {
+		var c DrawConflict
+		var reds, created string
+		var accepted *string
+		if err := rows.Scan(&c.ID, &c.Issue, &c.Provider, &c.DrawDate, &reds, &c.Blue, &c.Reason, &accepted, &created); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(reds), &c.Reds)
+		if accepted != nil {
+			var d Draw
+			if json.Unmarshal([]byte(*accepted), &d) == nil {
+				c.Accepted = &d
+			}
+		}
+		if t, e := parseTimeFlexible(created); e == nil {
+			c.CreatedAt = t
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,65 @@
+package store
+
+import "testing"
+
+func conflict(provider string, reds ...int) DrawConflict {
+	return DrawConflict{Issue: "2024098", Provider: provider, DrawDate: "2024-08-25", Reds: reds, Blue: 12, Reason: "disagree"}
+}
+
+func TestRecordConflictsIgnoresRepeats(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// 第二次为定时拉取重复遇到同一分歧（号码顺序不同也视为同一组）
+	first := []DrawConflict{conflict("jisu", 3, 9, 14, 20, 27, 32)}
+	again := []DrawConflict{conflict("jisu", 32, 27, 20, 14, 9, 3), conflict("mxnzp", 3, 9, 14, 20, 27, 33)}
+	for _, list := range [][]DrawConflict{first, again, again} {
+		if err := s.RecordConflicts(list); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.ListConflicts("2024098", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d conflicts, want 2: %+v", len(got), got)
+	}
+}
+
+// 升级前已积累的重复记录在迁移时去重，保留最早一条
+func TestMigrateDedupesConflicts(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 退回到唯一索引之前的状态并写入重复记录
+	if _, err := s.db.Exec(`DROP INDEX ux_draw_conflicts_report;
+DELETE FROM schema_migrations WHERE version >= 9;`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.db.Exec(`INSERT INTO draw_conflicts(issue, provider, draw_date, reds, blue, reason, created_at)
+VALUES('2024098', 'jisu', '2024-08-25', '[3,9,14,20,27,32]', 12, 'disagree', ?)`, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Close()
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	got, err := s.ListConflicts("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("after migration: %+v", got)
+	}
+}
@@ -0,0 +1,19 @@
+package store
+
+/* ----------------------------- SQLite 驱动 ----------------------------- */
+
+// 默认 mattn/go-sqlite3（CGO）；以 -tags purego 构建时改用 modernc.org/sqlite（纯 Go，可 CGO_ENABLED=0 交叉编译）。
+// 两者只在驱动名与 DSN 写法上不同，见 driver_cgo.go / driver_purego.go。
+
+type pragma struct{ name, value string }
+
+// 每个连接都需要的 PRAGMA；journal_mode 持久化在库文件里，放这里只是保证新库第一次打开即为 WAL
+var connPragmas = []pragma{
+	{"busy_timeout", "5000"},
+	{"foreign_keys", "1"},
+	{"journal_mode", "WAL"},
+	{"synchronous", "NORMAL"},
+}
+
+// DriverName：当前构建使用的 database/sql 驱动名（sqlite3 = mattn，sqlite = modernc）
+func DriverName() string { return driverName }
@@ -0,0 +1,20 @@
+//go:build !purego
+
+package store
+
+import (
+	"net/url"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const driverName = "sqlite3"
+
+// mattn：file.db?_busy_timeout=5000&_foreign_keys=1...
+func dsn(path string, pragmas []pragma) string {
+	q := url.Values{}
+	for _, p := range pragmas {
+		q.Set("_"+p.name, p.value)
+	}
+	return "file:" + path + "?" + q.Encode()
+}
@@ -0,0 +1,21 @@
+//go:build purego
+
+package store
+
+import (
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+const driverName = "sqlite"
+
+// modernc：file:file.db?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)...
+func dsn(path string, pragmas []pragma) string {
+	q := url.Values{}
+	for _, p := range pragmas {
+		q.Add("_pragma", fmt.Sprintf("%s(%s)", p.name, p.value))
+	}
+	return "file:" + path + "?" + q.Encode()
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+// 两种驱动的 DSN 写法不同；确认连接级 PRAGMA 在当前驱动下都生效（make test 会分别用两种驱动跑）
+func TestDriverPragmas(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, p := range connPragmas {
+		var got string
+		if err := s.db.QueryRow(`PRAGMA ` + p.name).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", p.name, err)
+		}
+		want := p.value
+		if p.name == "synchronous" {
+			want = "1" // NORMAL
+		}
+		if !strings.EqualFold(got, want) {
+			t.Errorf("%s (driver %s) = %q, want %q", p.name, DriverName(), got, want)
+		}
+	}
+}
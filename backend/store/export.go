@@ -0,0 +1,218 @@
+package store
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+/* ----------------------------- 导出 ----------------------------- */
+
+// From/To 可以是期号（YYYYNNN）或日期（YYYY-MM-DD 等），含端点；空 = 不限
+type ExportOptions struct {
+	From  string
+	To    string
+	Slips bool // 同时导出已保存的票（slips）
+}
+
+type Export struct {
+	Draws []Draw `json:"draws"`
+	Slips []Slip `json:"slips,omitempty"`
+}
+
+// 导出的 Sheet 名：开奖数据沿用 ImportExcel 读取的 Sheet1，票单独放一页
+const (
+	exportDrawSheet = "Sheet1"
+	exportSlipSheet = "Slips"
+)
+
+// ExportData：按区间取出开奖（按日期升序）与可选的票。
+// 期号边界作用于 draws.issue / slips.issue；日期边界作用于 draw_date / slip 创建日期
+func (s *Store) ExportData(opt ExportOptions) (*Export, error) {
+	from, to := exportBound(opt.From), exportBound(opt.To)
+	draws, err := s.ListRecentDraws(0)
+	if err != nil {
+		return nil, err
+	}
+	ex := &Export{Draws: []Draw{}}
+	for _, d := range draws {
+		if from.before(d.Issue, d.DrawDate) || to.after(d.Issue, d.DrawDate) {
+			continue
+		}
+		ex.Draws = append(ex.Draws, d)
+	}
+	if !opt.Slips {
+		return ex, nil
+	}
+	slips, err := s.ListSlips("")
+	if err != nil {
+		return nil, err
+	}
+	ex.Slips = []Slip{}
+	for i := len(slips) - 1; i >= 0; i-- { // ListSlips 为倒序；导出按创建顺序
+		sl := slips[i]
+		day := sl.CreatedAt.Local().Format("2006-01-02")
+		if from.before(sl.Issue, day) || to.after(sl.Issue, day) {
+			continue
+		}
+		ex.Slips = append(ex.Slips, sl)
+	}
+	return ex, nil
+}
+
+type bound struct {
+	value  string
+	isDate bool
+}
+
+func exportBound(s string) bound {
+	s = strings.TrimSpace(s)
+	if looksLikeDate(s) {
+		return bound{value: NormalizeDate(s), isDate: true}
+	}
+	return bound{value: s}
+}
+
+// 期号为空的行无法按期号比较，不受期号边界限制
+func (b bound) key(issue, date string) string {
+	if b.isDate {
+		return date
+	}
+	return issue
+}
+
+func (b bound) before(issue, date string) bool {
+	k := b.key(issue, date)
+	return b.value != "" && k != "" && k < b.value
+}
+
+func (b bound) after(issue, date string) bool {
+	k := b.key(issue, date)
+	return b.value != "" && k != "" && k > b.value
+}
+
+/* ---------------------------- 写出格式 ---------------------------- */
+
+// WriteXLSX：Sheet1 为 ImportExcel 的旧版布局（第1/2行表头；A 期号；B 日期；C 列 7 行号码），
+// 另加 D 来源、E 获取时间，导出后可原样导回；有票时追加 Slips 页（每注一行）
+func (ex *Export) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), exportDrawSheet); err != nil {
+		return err
+	}
+	rows := [][]any{
+		{"双色球开奖历史", "", "", "", ""},
+		{"期号", "日期", "号码", "来源", "获取时间"},
+	}
+	for _, d := range ex.Draws {
+		rows = append(rows, []any{d.Issue, d.DrawDate, codesCell(d.Reds, d.Blue), d.Source, fetchedCell(d)})
+	}
+	if err := writeSheetRows(f, exportDrawSheet, rows); err != nil {
+		return err
+	}
+	if ex.Slips != nil {
+		if _, err := f.NewSheet(exportSlipSheet); err != nil {
+			return err
+		}
+		rows = [][]any{{"slip_id", "name", "issue", "seed", "idx", "type", "bankers", "reds", "blues", "config", "created_at"}}
+		for _, sl := range ex.Slips {
+			for i, t := range sl.Tickets {
+				rows = append(rows, []any{
+					sl.ID, sl.Name, sl.Issue, strconv.FormatInt(sl.Seed, 10), i, ticketKind(t),
+					joinInts(t.Bankers, " "), joinInts(t.Reds, " "), joinInts(t.Blues, " "),
+					string(sl.Config), sl.CreatedAt.Format(time.RFC3339),
+				})
+			}
+		}
+		if err := writeSheetRows(f, exportSlipSheet, rows); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
+
+// WriteCSV：issue,draw_date,r1..r6,blue,source,fetched_at（ImportFile 可按表头自动识别）。CSV 只有一张表，不含票
+func (ex *Export) WriteCSV(w io.Writer) error {
+	if ex.Slips != nil {
+		return fmt.Errorf("csv export does not support slips; use xlsx or json")
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"issue", "draw_date", "r1", "r2", "r3", "r4", "r5", "r6", "blue", "source", "fetched_at"}); err != nil {
+		return err
+	}
+	for _, d := range ex.Draws {
+		rec := []string{d.Issue, d.DrawDate}
+		for _, r := range d.Reds {
+			rec = append(rec, fmt.Sprintf("%02d", r))
+		}
+		rec = append(rec, fmt.Sprintf("%02d", d.Blue), d.Source, fetchedCell(d))
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON：不含票时为 Draw 数组（ImportFile 可直接导回）；含票时为 {draws, slips}
+func (ex *Export) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if ex.Slips == nil {
+		return enc.Encode(ex.Draws)
+	}
+	return enc.Encode(ex)
+}
+
+func writeSheetRows(f *excelize.File, sheet string, rows [][]any) error {
+	for i, r := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 获取时间按 RFC3339 写出，未知为空；导回时原样保留
+func fetchedCell(d Draw) string {
+	if d.FetchedAt.IsZero() {
+		return ""
+	}
+	return d.FetchedAt.Format(time.RFC3339Nano)
+}
+
+// 7 个两位号码，每个一行（6 红 + 1 蓝）
+func codesCell(reds []int, blue int) string {
+	lines := make([]string, 0, 7)
+	for _, v := range reds {
+		lines = append(lines, fmt.Sprintf("%02d", v))
+	}
+	lines = append(lines, fmt.Sprintf("%02d", blue))
+	return strings.Join(lines, "\n")
+}
+
+func joinInts(a []int, sep string) string {
+	s := make([]string, len(a))
+	for i, v := range a {
+		s[i] = fmt.Sprintf("%02d", v)
+	}
+	return strings.Join(s, sep)
+}
+
+func ticketKind(t Ticket) string {
+	if t.Kind == "" {
+		return "single"
+	}
+	return t.Kind
+}
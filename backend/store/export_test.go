@@ -0,0 +1,89 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// 导出后再导入：号码、来源与获取时间原样保留，crawler 行在之后的合并导入中仍受保护
+func TestExportImportRoundTrip(t *testing.T) {
+	src, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	fetched := time.Date(2024, 1, 2, 21, 30, 0, 0, time.UTC)
+	want := []Draw{
+		{Issue: "2024001", DrawDate: "2024-01-02", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9, Source: sourceCrawler, FetchedAt: fetched},
+		{Issue: "2024002", DrawDate: "2024-01-04", Reds: []int{2, 6, 13, 19, 26, 32}, Blue: 10, Source: "manual", FetchedAt: fetched.Add(48 * time.Hour)},
+	}
+	for _, d := range want {
+		if err := src.UpsertDrawByIssue(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ex, err := src.ExportData(ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writers := map[string]func(*Export, *bytes.Buffer) error{
+		"xlsx": func(ex *Export, b *bytes.Buffer) error { return ex.WriteXLSX(b) },
+		"csv":  func(ex *Export, b *bytes.Buffer) error { return ex.WriteCSV(b) },
+		"json": func(ex *Export, b *bytes.Buffer) error { return ex.WriteJSON(b) },
+	}
+	for format, write := range writers {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := write(ex, &buf); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "export."+format)
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			dst, err := Open(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer dst.Close()
+			rep, err := dst.ImportFile(path, ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rep.Format != format || rep.Imported != len(want) || len(rep.Rejected) != 0 {
+				t.Fatalf("report = %+v", rep)
+			}
+			got, err := dst.ListRecentDraws(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d draws, want %d", len(got), len(want))
+			}
+			for i, w := range want {
+				g := got[i]
+				if g.Issue != w.Issue || g.DrawDate != w.DrawDate || !slices.Equal(g.Reds, w.Reds) || g.Blue != w.Blue ||
+					g.Source != w.Source || !g.FetchedAt.Equal(w.FetchedAt) {
+					t.Errorf("draw %d = %+v, want %+v", i, g, w)
+				}
+			}
+
+			// 导回的 crawler 行不会被后续文件覆盖
+			changed := writeFile(t, "changed.json", `[{"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,33],"blue":9}]`)
+			rep, err = dst.ImportFile(changed, ImportOptions{Merge: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rep.Merge.Kept) != 1 || len(rep.Merge.Updated) != 0 {
+				t.Fatalf("merge after round trip: %+v", rep.Merge)
+			}
+		})
+	}
+}
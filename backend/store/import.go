@@ -0,0 +1,421 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* ----------------------------- 导入报告 ----------------------------- */
+
+type ImportOptions struct {
+	Replace bool // 先清空 draws 再导入；否则库非空时返回 ErrAlreadyInitialized
+	DryRun  bool // 只校验、不写库
+	Merge   bool // 按期号增量合并（不删除任何行）；优先于 Replace
+
+	Format  string         // xlsx | csv | json | jsonl；空 = 按内容嗅探（仅 ImportFile）
+	Columns *ColumnMapping // 表格列映射；nil = 自动识别（ImportExcel 为旧版固定布局）
+	Audit   AuditMeta      // 写入 draw_audit 的操作人/渠道；空则记为 system / import
+}
+
+func (o ImportOptions) auditMeta() AuditMeta {
+	m := o.Audit
+	if m.Actor == "" {
+		m.Actor = "system"
+	}
+	if m.Source == "" {
+		m.Source = "import"
+	}
+	return m
+}
+
+// 被拒绝的行；Row 为表格中的行号（1 起，与 Excel 左侧行号一致）
+type RejectedRow struct {
+	Row    int    `json:"row"`
+	Issue  string `json:"issue,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type ImportWarning struct {
+	Row   int    `json:"row"`
+	Issue string `json:"issue,omitempty"`
+	Kind  string `json:"kind"` // duplicate_issue | date_order | crawler_kept
+	Msg   string `json:"msg"`
+}
+
+type ImportReport struct {
+	Format   string          `json:"format,omitempty"`
+	Sheet    string          `json:"sheet,omitempty"`
+	Columns  *ColumnMapping  `json:"columns,omitempty"` // 实际使用的列映射
+	Rows     int             `json:"rows"`              // 检查的数据行（不含表头与空行）
+	Accepted int             `json:"accepted"`          // 通过校验的行
+	Imported int             `json:"imported"`          // 实际写入（dry-run 为 0）
+	DryRun   bool            `json:"dry_run"`
+	Rejected []RejectedRow   `json:"rejected"`
+	Warnings []ImportWarning `json:"warnings"`
+	Merge    *MergeResult    `json:"merge,omitempty"`
+}
+
+// 合并导入结果；dry-run 时为"将会"发生的变化
+type MergeResult struct {
+	Inserted  []string      `json:"inserted"`
+	Updated   []MergeChange `json:"updated"`
+	Unchanged int           `json:"unchanged"`
+	Kept      []MergeChange `json:"kept"` // 库中为 crawler 来源且与文件不一致：保留库中数据
+}
+
+type MergeChange struct {
+	Row  int  `json:"row"`
+	Prev Draw `json:"prev"`
+	Draw Draw `json:"draw"`
+}
+
+func newImportReport(dryRun bool) *ImportReport {
+	return &ImportReport{DryRun: dryRun, Rejected: []RejectedRow{}, Warnings: []ImportWarning{}}
+}
+
+func (r *ImportReport) reject(row int, issue, format string, args ...any) {
+	r.Rejected = append(r.Rejected, RejectedRow{Row: row, Issue: issue, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (r *ImportReport) warn(row int, issue, kind, format string, args ...any) {
+	r.Warnings = append(r.Warnings, ImportWarning{Row: row, Issue: issue, Kind: kind, Msg: fmt.Sprintf(format, args...)})
+}
+
+// 通过单行校验、待写入的一期
+type importRow struct {
+	Row  int
+	Draw Draw
+}
+
+// 文件未带来源时按导入格式记（excel/csv/json…），未带获取时间时记为导入时间
+func (r importRow) withProvenance(source string, now time.Time) Draw {
+	d := r.Draw
+	if d.Source == "" {
+		d.Source = source
+	}
+	if d.FetchedAt.IsZero() {
+		d.FetchedAt = now
+	}
+	return d
+}
+
+/* -------------------------- Import from Excel ------------------------- */
+
+// 初始化/覆盖导入 Excel（sheet1；第1/2行为表头；第2列日期；第3列为 7 行号码）。
+// 每个被跳过的行都会出现在报告里；opt.DryRun 时只校验不写库。
+func (s *Store) ImportExcel(xlsxPath string, opt ImportOptions) (*ImportReport, error) {
+	rep := newImportReport(opt.DryRun)
+	rep.Format = "xlsx"
+	records, sheet, err := readSheet(xlsxPath)
+	if err != nil {
+		return nil, err
+	}
+	rep.Sheet = sheet
+	cols := legacyExcelColumns
+	if opt.Columns != nil {
+		cols = *opt.Columns
+	}
+	rows, err := mappedRows(records, func(i int) int { return i + 1 }, &cols, rep)
+	if err != nil {
+		return rep, err
+	}
+	return rep, s.writeImport(rows, "excel", opt, rep)
+}
+
+// 日期 + 7 个号码（6 红 + 1 蓝）→ Draw；号码允许前后空白
+func parseDrawCells(issue, date string, nums []string) (Draw, error) {
+	d := Draw{Issue: strings.TrimSpace(issue)}
+	if strings.TrimSpace(date) == "" {
+		return d, fmt.Errorf("empty date")
+	}
+	d.DrawDate = NormalizeDate(date)
+	if _, err := time.Parse("2006-01-02", d.DrawDate); err != nil {
+		return d, fmt.Errorf("invalid date: %q", strings.TrimSpace(date))
+	}
+	var vals []string
+	for _, n := range nums {
+		if n = strings.TrimSpace(n); n != "" {
+			vals = append(vals, n)
+		}
+	}
+	if len(vals) != 7 {
+		return d, fmt.Errorf("expected 7 numbers (6 red + 1 blue), got %d", len(vals))
+	}
+	reds := make([]int, 0, 6)
+	seen := map[int]bool{}
+	for j := 0; j < 6; j++ {
+		var n int
+		if _, e := fmt.Sscanf(vals[j], "%d", &n); e != nil {
+			return d, fmt.Errorf("invalid red #%d: %q", j+1, vals[j])
+		}
+		if n < 1 || n > 33 {
+			return d, fmt.Errorf("red out of range: %d", n)
+		}
+		if seen[n] {
+			return d, fmt.Errorf("duplicate red: %d", n)
+		}
+		seen[n] = true
+		reds = append(reds, n)
+	}
+	sort.Ints(reds)
+	var blue int
+	if _, e := fmt.Sscanf(vals[6], "%d", &blue); e != nil {
+		return d, fmt.Errorf("invalid blue: %q", vals[6])
+	}
+	if blue < 1 || blue > 16 {
+		return d, fmt.Errorf("blue out of range: %d", blue)
+	}
+	d.Reds, d.Blue = reds, blue
+	return d, nil
+}
+
+func blankRow(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+/* ------------------------------ 写入 ------------------------------ */
+
+// 跨行检查：重复期号（保留首次出现）、日期顺序与整体方向不一致
+func checkRows(rows []importRow, rep *ImportReport) []importRow {
+	out := make([]importRow, 0, len(rows))
+	firstRow := map[string]int{}
+	dir := 0 // 1 升序；-1 降序；按前两个不同日期判定
+	var prev *importRow
+	for i := range rows {
+		r := &rows[i]
+		if r.Draw.Issue != "" {
+			if at, dup := firstRow[r.Draw.Issue]; dup {
+				rep.warn(r.Row, r.Draw.Issue, "duplicate_issue", "duplicate issue (first at row %d); row ignored", at)
+				continue
+			}
+			firstRow[r.Draw.Issue] = r.Row
+		}
+		if prev != nil && r.Draw.DrawDate != prev.Draw.DrawDate {
+			d := 1
+			if r.Draw.DrawDate < prev.Draw.DrawDate {
+				d = -1
+			}
+			if dir == 0 {
+				dir = d
+			} else if d != dir {
+				rep.warn(r.Row, r.Draw.Issue, "date_order", "date %s out of order after %s (row %d)", r.Draw.DrawDate, prev.Draw.DrawDate, prev.Row)
+			}
+		}
+		prev = r
+		out = append(out, *r)
+	}
+	return out
+}
+
+// 初始化/覆盖导入：与库中原有数据逐期比较后写审计（insert/update/delete）；
+// 覆盖掉的期同时清除其兑奖结果，提交后对 inserted/updated 触发 DrawHook（与合并导入一致）
+func (s *Store) writeImport(rows []importRow, source string, opt ImportOptions, rep *ImportReport) (err error) {
+	rows = checkRows(rows, rep)
+	rep.Accepted = len(rows)
+	if opt.Merge {
+		return s.mergeImport(rows, source, opt, rep)
+	}
+	if opt.DryRun {
+		return nil
+	}
+
+	has, err := s.hasAny()
+	if err != nil {
+		return err
+	}
+	if has && !opt.Replace {
+		return ErrAlreadyInitialized
+	}
+
+	type change struct {
+		d      Draw
+		status string
+	}
+	var changed []change
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			return
+		}
+		for _, c := range changed {
+			s.fireDrawChanged(c.d, c.status)
+		}
+	}()
+
+	meta := opt.auditMeta()
+	old := map[string]Draw{}
+	if opt.Replace {
+		if old, err = drawsByIssue(tx); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(`DELETE FROM draws`); err != nil {
+			return err
+		}
+	}
+
+	stmt, err := tx.Prepare(insertDrawSQL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, r := range rows {
+		d := r.withProvenance(source, now)
+		if _, err = stmt.Exec(insertDrawArgs(nullIfEmpty(d.Issue), d, d.Source, d.FetchedAt.Format(time.RFC3339Nano))...); err != nil {
+			return fmt.Errorf("row %d: %w", r.Row, err)
+		}
+		rep.Imported++
+
+		prev, had := old[d.Issue]
+		delete(old, d.Issue)
+		switch {
+		case !had:
+			err = insertAudit(tx, d.Issue, "insert", nil, &d, meta)
+			changed = append(changed, change{d, "inserted"})
+		case !sameDraw(prev, d) || prev.Source != d.Source:
+			err = insertAudit(tx, d.Issue, "update", &prev, &d, meta)
+			changed = append(changed, change{d, "updated"})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// 文件中没有的期被覆盖删除
+	gone := make([]string, 0, len(old))
+	for issue := range old {
+		gone = append(gone, issue)
+	}
+	sort.Strings(gone)
+	for _, issue := range gone {
+		prev := old[issue]
+		if err = insertAudit(tx, issue, "delete", &prev, nil, meta); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(`DELETE FROM slip_results WHERE issue=?`, issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 库中带期号的开奖，按期号索引
+func drawsByIssue(q interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}) (map[string]Draw, error) {
+	rows, err := q.Query(`SELECT ` + drawCols + ` FROM draws WHERE issue IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]Draw{}
+	for rows.Next() {
+		var r drawRow
+		if err := rows.Scan(r.dest()...); err != nil {
+			return nil, err
+		}
+		d := r.draw()
+		out[d.Issue] = d
+	}
+	return out, rows.Err()
+}
+
+/* ------------------------------ 合并 ------------------------------ */
+
+// crawler 来源（第三方接口）的数据不会被导入文件覆盖
+const sourceCrawler = "crawler"
+
+// mergeImport：按期号 upsert。新期插入；内容不同则更新（crawler 行除外）；不删除任何行。
+// 提交后对 inserted/updated 触发 DrawHook（与 ReconcileIssue 一致）
+func (s *Store) mergeImport(rows []importRow, source string, opt ImportOptions, rep *ImportReport) (err error) {
+	dryRun, meta := opt.DryRun, opt.auditMeta()
+	mr := &MergeResult{Inserted: []string{}, Updated: []MergeChange{}, Kept: []MergeChange{}}
+	rep.Merge = mr
+	var inserted []Draw
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil || dryRun {
+			_ = tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			return
+		}
+		for _, d := range inserted {
+			s.fireDrawChanged(d, "inserted")
+		}
+		for _, c := range mr.Updated {
+			s.fireDrawChanged(c.Draw, "updated")
+		}
+	}()
+
+	now := time.Now()
+	for _, r := range rows {
+		d := r.withProvenance(source, now)
+		if d.Issue == "" {
+			rep.reject(r.Row, "", "issue required for merge")
+			rep.Accepted--
+			continue
+		}
+		var row drawRow
+		e := tx.QueryRow(`SELECT `+drawCols+` FROM draws WHERE issue=?`, d.Issue).Scan(row.dest()...)
+		switch {
+		case e == sql.ErrNoRows:
+			if _, err = tx.Exec(insertDrawSQL, insertDrawArgs(d.Issue, d, d.Source, d.FetchedAt.Format(time.RFC3339Nano))...); err != nil {
+				return fmt.Errorf("row %d: %w", r.Row, err)
+			}
+			if err = insertAudit(tx, d.Issue, "insert", nil, &d, meta); err != nil {
+				return err
+			}
+			mr.Inserted = append(mr.Inserted, d.Issue)
+			inserted = append(inserted, d)
+			if !dryRun {
+				rep.Imported++
+			}
+			continue
+		case e != nil:
+			return e
+		}
+		prev := row.draw()
+
+		switch {
+		case sameDraw(prev, d):
+			mr.Unchanged++
+		case prev.Source == sourceCrawler:
+			mr.Kept = append(mr.Kept, MergeChange{Row: r.Row, Prev: prev, Draw: d})
+			rep.warn(r.Row, d.Issue, "crawler_kept", "differs from crawler data; kept existing row")
+		default:
+			if _, err = tx.Exec(updateDrawSQL, updateDrawArgs(d, d.Source, d.FetchedAt.Format(time.RFC3339Nano))...); err != nil {
+				return fmt.Errorf("row %d: %w", r.Row, err)
+			}
+			if err = insertAudit(tx, d.Issue, "update", &prev, &d, meta); err != nil {
+				return err
+			}
+			mr.Updated = append(mr.Updated, MergeChange{Row: r.Row, Prev: prev, Draw: d})
+			if !dryRun {
+				rep.Imported++
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,513 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+/* ----------------------------- 列映射 ----------------------------- */
+
+// ColumnMapping：表格（xlsx/CSV）各字段所在列（0 起；-1 表示没有该列）。
+// 号码三选一：Line（"01,02,03,04,05,06+07" 单元格）、Codes（7 个号码换行分隔的单元格）、Reds+Blue（每列一个号码）。
+type ColumnMapping struct {
+	HeaderRows int   `json:"header_rows"` // 表头行数；<0 自动（跳过日期列无法解析的前导行）
+	Issue      int   `json:"issue"`
+	Date       int   `json:"date"`
+	Line       int   `json:"line"`
+	Codes      int   `json:"codes"`
+	Reds       []int `json:"reds,omitempty"` // 6 列
+	Blue       int   `json:"blue"`
+	Source     int   `json:"source"`     // 可选：来源（导出文件带回，空则按文件格式）
+	FetchedAt  int   `json:"fetched_at"` // 可选：获取时间（空则为导入时间）
+}
+
+// 旧版 Excel 布局：前两行表头；期号/日期/7 行号码；导出文件另有来源/获取时间两列（旧文件为空）
+var legacyExcelColumns = ColumnMapping{HeaderRows: 2, Issue: 0, Date: 1, Line: -1, Codes: 2, Blue: -1, Source: 3, FetchedAt: 4}
+
+func emptyMapping() ColumnMapping {
+	return ColumnMapping{HeaderRows: -1, Issue: -1, Date: -1, Line: -1, Codes: -1, Blue: -1, Source: -1, FetchedAt: -1}
+}
+
+// 未出现的字段视为 -1（没有该列），而不是第 0 列
+func (m *ColumnMapping) UnmarshalJSON(b []byte) error {
+	type plain ColumnMapping
+	v := plain(emptyMapping())
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*m = ColumnMapping(v)
+	return nil
+}
+
+func (m ColumnMapping) valid() error {
+	if m.Date < 0 {
+		return fmt.Errorf("date column required")
+	}
+	switch {
+	case m.Line >= 0, m.Codes >= 0:
+	case len(m.Reds) == 6 && m.Blue >= 0:
+	default:
+		return fmt.Errorf("numbers column(s) required: line, codes or 6 reds + blue")
+	}
+	return nil
+}
+
+// 表头名 → 字段（小写、去空白后比较）
+var headerNames = map[string]string{
+	"期号": "issue", "issue": "issue", "expect": "issue", "issueno": "issue", "期数": "issue",
+	"日期": "date", "开奖日期": "date", "date": "date", "draw_date": "date", "opendate": "date", "time": "date",
+	"号码": "line", "开奖号码": "line", "numbers": "line", "opencode": "line", "code": "line",
+	"蓝球": "blue", "蓝": "blue", "blue": "blue", "b": "blue",
+	"来源": "source", "source": "source",
+	"获取时间": "fetched_at", "fetched_at": "fetched_at",
+}
+
+func headerField(name string) (string, int) {
+	n := strings.ToLower(strings.Join(strings.Fields(name), ""))
+	if f, ok := headerNames[n]; ok {
+		return f, 0
+	}
+	// 红1..红6 / red1..red6 / r1..r6
+	for _, p := range []string{"红球", "红", "red", "r"} {
+		if rest, ok := strings.CutPrefix(n, p); ok {
+			if k, err := strconv.Atoi(rest); err == nil && k >= 1 && k <= 6 {
+				return "red", k
+			}
+		}
+	}
+	return "", 0
+}
+
+// 按表头名映射；至少识别出日期与号码才算成功
+func mappingFromHeader(row []string) (ColumnMapping, bool) {
+	m := emptyMapping()
+	reds := make([]int, 6)
+	nReds := 0
+	for i, c := range row {
+		f, k := headerField(c)
+		switch f {
+		case "issue":
+			m.Issue = i
+		case "date":
+			m.Date = i
+		case "line":
+			m.Line = i
+		case "blue":
+			m.Blue = i
+		case "source":
+			m.Source = i
+		case "fetched_at":
+			m.FetchedAt = i
+		case "red":
+			reds[k-1] = i
+			nReds++
+		}
+	}
+	if nReds == 6 {
+		m.Reds = reds
+	}
+	return m, m.valid() == nil
+}
+
+// 按数据行内容推断
+func mappingFromData(row []string) (ColumnMapping, bool) {
+	m := emptyMapping()
+	var ints []int
+	for i, raw := range row {
+		c := strings.TrimSpace(raw)
+		switch {
+		case c == "":
+		case m.Issue < 0 && looksLikeIssue(c):
+			m.Issue = i
+		case m.Date < 0 && looksLikeDate(c):
+			m.Date = i
+		case m.Line < 0 && strings.Contains(c, "+"):
+			m.Line = i
+		case m.Codes < 0 && len(strings.Fields(c)) == 7 && strings.Contains(c, "\n"):
+			m.Codes = i
+		default:
+			if n, err := strconv.Atoi(c); err == nil && n >= 1 && n <= 33 {
+				ints = append(ints, i)
+			}
+		}
+	}
+	if m.Line < 0 && m.Codes < 0 && len(ints) >= 7 {
+		m.Reds, m.Blue = ints[:6], ints[6]
+	}
+	return m, m.valid() == nil
+}
+
+func looksLikeIssue(s string) bool {
+	if len(s) != 7 || !(strings.HasPrefix(s, "19") || strings.HasPrefix(s, "20")) {
+		return false
+	}
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func looksLikeDate(s string) bool {
+	if !strings.ContainsAny(s, "-/.") {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", NormalizeDate(s))
+	return err == nil
+}
+
+// 在前 10 行中找第一行数据：先看它上一行是否为可识别的表头，否则按内容推断
+func detectMapping(records [][]string) (ColumnMapping, bool) {
+	for i := 0; i < len(records) && i < 10; i++ {
+		dm, ok := mappingFromData(records[i])
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			if hm, ok := mappingFromHeader(records[i-1]); ok {
+				hm.HeaderRows = i
+				// "号码"列内容为 7 行号码（旧版布局/导出文件）而非 "…+蓝"
+				if hm.Line >= 0 && hm.Line == dm.Codes {
+					hm.Line, hm.Codes = -1, dm.Codes
+				}
+				return hm, true
+			}
+		}
+		dm.HeaderRows = i
+		return dm, true
+	}
+	return ColumnMapping{}, false
+}
+
+/* ----------------------------- 表格 → 行 ----------------------------- */
+
+// records 的第 i 条对应表格行号 lineOf(i)
+func tableRows(records [][]string, lineOf func(int) int, m ColumnMapping, rep *ImportReport) []importRow {
+	cell := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+	start := m.HeaderRows
+	if start < 0 {
+		start = 0
+		for start < len(records) && start < 10 && !looksLikeDate(cell(records[start], m.Date)) {
+			start++
+		}
+	}
+
+	var out []importRow
+	for i := start; i < len(records); i++ {
+		row, lineNo := records[i], lineOf(i)
+		if blankRow(row) {
+			continue
+		}
+		rep.Rows++
+		issue := cell(row, m.Issue)
+
+		var nums []string
+		switch {
+		case m.Line >= 0:
+			left, right, ok := strings.Cut(cell(row, m.Line), "+")
+			if !ok {
+				rep.reject(lineNo, issue, "missing '+' in numbers: %q", cell(row, m.Line))
+				continue
+			}
+			nums = append(strings.FieldsFunc(left, func(r rune) bool {
+				return r == ',' || r == '，' || r == ' ' || r == '\t'
+			}), right)
+		case m.Codes >= 0:
+			nums = strings.Split(cell(row, m.Codes), "\n")
+		default:
+			for _, c := range m.Reds {
+				nums = append(nums, cell(row, c))
+			}
+			nums = append(nums, cell(row, m.Blue))
+		}
+		d, err := parseDrawCells(issue, cell(row, m.Date), nums)
+		if err == nil {
+			err = setProvenance(&d, cell(row, m.Source), cell(row, m.FetchedAt))
+		}
+		if err != nil {
+			rep.reject(lineNo, issue, "%v", err)
+			continue
+		}
+		out = append(out, importRow{Row: lineNo, Draw: d})
+	}
+	return out
+}
+
+/* ----------------------------- 各格式读取 ----------------------------- */
+
+// 根据内容判断格式：xlsx（zip 头）/ json（数组）/ jsonl（对象行）/ csv（其余）
+func SniffFormat(head []byte) string {
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return "xlsx"
+	}
+	t := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(t, []byte("[")):
+		return "json"
+	case bytes.HasPrefix(t, []byte("{")):
+		return "jsonl"
+	default:
+		return "csv"
+	}
+}
+
+// ImportFile：按 opt.Format（为空则嗅探内容）选择导入器；表格格式按 opt.Columns 或自动识别列
+func (s *Store) ImportFile(path string, opt ImportOptions) (*ImportReport, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.ToLower(opt.Format)
+	if format == "" {
+		format = SniffFormat(body)
+	}
+	rep := newImportReport(opt.DryRun)
+	rep.Format = format
+
+	var rows []importRow
+	switch format {
+	case "xlsx":
+		records, sheet, err := readSheet(path)
+		if err != nil {
+			return nil, err
+		}
+		rep.Sheet = sheet
+		rows, err = mappedRows(records, func(i int) int { return i + 1 }, opt.Columns, rep)
+		if err != nil {
+			return rep, err
+		}
+	case "csv":
+		records, lines, err := readCSV(body)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = mappedRows(records, func(i int) int { return lines[i] }, opt.Columns, rep)
+		if err != nil {
+			return rep, err
+		}
+	case "json", "jsonl":
+		rows, err = jsonRows(body, format, rep)
+		if err != nil {
+			return rep, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	source := format
+	if format == "xlsx" {
+		source = "excel"
+	}
+	return rep, s.writeImport(rows, source, opt, rep)
+}
+
+func mappedRows(records [][]string, lineOf func(int) int, m *ColumnMapping, rep *ImportReport) ([]importRow, error) {
+	var use ColumnMapping
+	if m != nil {
+		use = *m
+		if err := use.valid(); err != nil {
+			return nil, fmt.Errorf("columns: %w", err)
+		}
+	} else {
+		var ok bool
+		if use, ok = detectMapping(records); !ok {
+			return nil, fmt.Errorf("cannot detect columns; pass columns mapping")
+		}
+	}
+	rep.Columns = &use
+	return tableRows(records, lineOf, use, rep), nil
+}
+
+func readSheet(path string) ([][]string, string, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	l := f.GetSheetList()
+	if len(l) == 0 {
+		return nil, "", fmt.Errorf("excel 文件无工作表")
+	}
+	sheet := l[0]
+	for _, name := range l {
+		if strings.EqualFold(name, "sheet1") {
+			sheet = name
+			break
+		}
+	}
+	rows, err := f.GetRows(sheet)
+	return rows, sheet, err
+}
+
+// 分隔符按首行判断（, ; \t）；返回每条记录的起始行号
+func readCSV(body []byte) ([][]string, []int, error) {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+	first, _, _ := bytes.Cut(body, []byte("\n"))
+	comma := ','
+	for _, c := range []rune{'\t', ';'} {
+		if bytes.Count(first, []byte(string(c))) > bytes.Count(first, []byte(string(comma))) {
+			comma = c
+		}
+	}
+	r := csv.NewReader(bytes.NewReader(body))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var records [][]string
+	var lines []int
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, err
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, rec)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
+// JSON：一期一个对象；字段名兼容 store.Draw、mxnzp（expect/openCode/time）、jisu（issueno/number/refernumber/opendate）
+type jsonDraw struct {
+	Issue    string          `json:"issue"`
+	Expect   string          `json:"expect"`
+	IssueNo  string          `json:"issueno"`
+	Date     string          `json:"draw_date"`
+	Date2    string          `json:"date"`
+	Time     string          `json:"time"`
+	OpenDate string          `json:"opendate"`
+	Reds     json.RawMessage `json:"reds"` // [1,2,...] 或 "01,02,..."
+	Blue     json.RawMessage `json:"blue"` // 7 或 "07"
+	Numbers  string          `json:"numbers"`
+	OpenCode string          `json:"openCode"`
+	Number   string          `json:"number"`
+	Refer    string          `json:"refernumber"`
+
+	// 本程序导出的 JSON 带回来源与获取时间
+	Source    string `json:"source"`
+	FetchedAt string `json:"fetched_at"`
+}
+
+func (j jsonDraw) cells() (issue, date string, nums []string, err error) {
+	issue = firstNonEmptyStr(j.Issue, j.Expect, j.IssueNo)
+	date = firstNonEmptyStr(j.Date, j.Date2, j.OpenDate, j.Time)
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' || r == ' ' || r == '\t' })
+	}
+	if line := firstNonEmptyStr(j.Numbers, j.OpenCode); line != "" {
+		left, right, ok := strings.Cut(line, "+")
+		if !ok {
+			return issue, date, nil, fmt.Errorf("missing '+' in numbers: %q", line)
+		}
+		return issue, date, append(split(left), right), nil
+	}
+	if j.Number != "" {
+		return issue, date, append(split(j.Number), j.Refer), nil
+	}
+	var reds []int
+	if err := json.Unmarshal(j.Reds, &reds); err == nil {
+		for _, v := range reds {
+			nums = append(nums, strconv.Itoa(v))
+		}
+	} else {
+		var s string
+		if err := json.Unmarshal(j.Reds, &s); err != nil {
+			return issue, date, nil, fmt.Errorf("reds missing or invalid")
+		}
+		nums = split(s)
+	}
+	nums = append(nums, strings.Trim(string(j.Blue), `" `))
+	return issue, date, nums, nil
+}
+
+func jsonRows(body []byte, format string, rep *ImportReport) ([]importRow, error) {
+	type item struct {
+		row int
+		raw json.RawMessage
+	}
+	var items []item
+	if format == "json" {
+		var list []json.RawMessage
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("json: %w", err)
+		}
+		for i, raw := range list {
+			items = append(items, item{row: i + 1, raw: raw}) // 数组下标（1 起）
+		}
+	} else {
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		sc.Buffer(make([]byte, 64*1024), 1<<20)
+		for n := 1; sc.Scan(); n++ {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			items = append(items, item{row: n, raw: append(json.RawMessage(nil), line...)})
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var out []importRow
+	for _, it := range items {
+		rep.Rows++
+		var j jsonDraw
+		if err := json.Unmarshal(it.raw, &j); err != nil {
+			rep.reject(it.row, "", "invalid json: %v", err)
+			continue
+		}
+		issue, date, nums, err := j.cells()
+		if err == nil {
+			var d Draw
+			if d, err = parseDrawCells(issue, date, nums); err == nil {
+				if err = setProvenance(&d, j.Source, j.FetchedAt); err == nil {
+					out = append(out, importRow{Row: it.row, Draw: d})
+					continue
+				}
+			}
+		}
+		rep.reject(it.row, issue, "%v", err)
+	}
+	return out, nil
+}
+
+// 文件中带的来源/获取时间（导出后再导入时保留）；为空的保持零值，写入时按文件格式/导入时间补齐。
+// 零时间（0001-01-01…）视为未填
+func setProvenance(d *Draw, source, fetched string) error {
+	d.Source = strings.TrimSpace(source)
+	if fetched = strings.TrimSpace(fetched); fetched == "" {
+		return nil
+	}
+	t, err := parseTimeFlexible(fetched)
+	if err != nil {
+		return fmt.Errorf("invalid fetched_at: %q", fetched)
+	}
+	if !t.IsZero() {
+		d.FetchedAt = t
+	}
+	return nil
+}
+
+func firstNonEmptyStr(ss ...string) string {
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return ""
+}
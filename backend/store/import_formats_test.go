@@ -0,0 +1,246 @@
+package store
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mapping(edit func(*ColumnMapping)) ColumnMapping {
+	m := emptyMapping()
+	edit(&m)
+	return m
+}
+
+func TestDetectMapping(t *testing.T) {
+	codes := "01\n05\n12\n18\n25\n31\n09"
+	cases := []struct {
+		name    string
+		records [][]string
+		want    ColumnMapping
+		ok      bool
+	}{
+		{
+			"header with per-column reds",
+			[][]string{
+				{"期号", "日期", "红1", "红2", "红3", "红4", "红5", "红6", "蓝球"},
+				{"2024001", "2024-01-02", "01", "05", "12", "18", "25", "31", "09"},
+			},
+			mapping(func(m *ColumnMapping) {
+				m.HeaderRows, m.Issue, m.Date, m.Reds, m.Blue = 1, 0, 1, []int{2, 3, 4, 5, 6, 7}, 8
+			}),
+			true,
+		},
+		{
+			"legacy excel layout (号码 column holds 7 lines)",
+			[][]string{
+				{"双色球开奖历史", "", ""},
+				{"期号", "日期", "号码"},
+				{"2024001", "2024-01-02", codes},
+			},
+			mapping(func(m *ColumnMapping) { m.HeaderRows, m.Issue, m.Date, m.Codes = 2, 0, 1, 2 }),
+			true,
+		},
+		{
+			"exported csv with source and fetched_at",
+			[][]string{
+				{"issue", "draw_date", "r1", "r2", "r3", "r4", "r5", "r6", "blue", "source", "fetched_at"},
+				{"2024001", "2024-01-02", "01", "05", "12", "18", "25", "31", "09", "crawler", "2024-01-02T21:30:00Z"},
+			},
+			mapping(func(m *ColumnMapping) {
+				m.HeaderRows, m.Issue, m.Date, m.Reds, m.Blue = 1, 0, 1, []int{2, 3, 4, 5, 6, 7}, 8
+				m.Source, m.FetchedAt = 9, 10
+			}),
+			true,
+		},
+		{
+			"no header, line cell",
+			[][]string{{"2024001", "2024/01/02", "01,05,12,18,25,31+09"}},
+			mapping(func(m *ColumnMapping) { m.HeaderRows, m.Issue, m.Date, m.Line = 0, 0, 1, 2 }),
+			true,
+		},
+		{
+			"unknown header, one number per column, no issue",
+			[][]string{
+				{"a", "b", "c", "d", "e", "f", "g", "h"},
+				{"2024.01.02", "1", "5", "12", "18", "25", "31", "9"},
+			},
+			mapping(func(m *ColumnMapping) { m.HeaderRows, m.Date, m.Reds, m.Blue = 1, 0, []int{1, 2, 3, 4, 5, 6}, 7 }),
+			true,
+		},
+		{
+			"nothing recognisable",
+			[][]string{{"hello", "world"}, {"1", "2"}},
+			ColumnMapping{},
+			false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := detectMapping(tc.records)
+			if ok != tc.ok || !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("detectMapping = %+v, %v; want %+v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSniffFormat(t *testing.T) {
+	cases := map[string]string{
+		"PK\x03\x04rest-of-zip":           "xlsx",
+		"\xef\xbb\xbf  \n[{\"issue\":1}]": "json",
+		"{\"issue\":\"2024001\"}\n{}":     "jsonl",
+		"issue,draw_date,r1":              "csv",
+		"期号;日期;号码":                        "csv",
+		"":                                "csv",
+	}
+	for head, want := range cases {
+		if got := SniffFormat([]byte(head)); got != want {
+			t.Errorf("SniffFormat(%q) = %s, want %s", head, got, want)
+		}
+	}
+}
+
+func TestReadCSVDelimiterAndLines(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		cols  int
+		lines []int
+	}{
+		{"comma", "issue,date,line\n2024001,2024-01-02,\"01,05,12,18,25,31+09\"\n", 3, []int{1, 2}},
+		{"semicolon with BOM", "\xef\xbb\xbfissue;date;line\n2024001;2024-01-02;01,05,12,18,25,31+09\n", 3, []int{1, 2}},
+		{"tab", "issue\tdate\tline\n2024001\t2024-01-02\t01,05,12,18,25,31+09\n", 3, []int{1, 2}},
+		// 引号内换行：下一条记录的起始行号跳过被占用的行
+		{"multi-line cell", "issue,date,codes\n2024001,2024-01-02,\"01\n05\n12\n18\n25\n31\n09\"\n2024002,2024-01-04,x\n", 3, []int{1, 2, 9}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			records, lines, err := readCSV([]byte(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, r := range records {
+				if len(r) != tc.cols {
+					t.Fatalf("record %d has %d fields: %q", i, len(r), r)
+				}
+			}
+			if !slices.Equal(lines, tc.lines) {
+				t.Fatalf("lines = %v, want %v", lines, tc.lines)
+			}
+			if records[0][0] != "issue" {
+				t.Fatalf("BOM not stripped: %q", records[0][0])
+			}
+		})
+	}
+}
+
+func TestJSONRows(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		body     string
+		accepted []string       // 通过的期号
+		rejected map[int]string // 行号 → 原因片段
+	}{
+		{
+			"array: store, mxnzp and jisu shapes",
+			"json",
+			`[
+  {"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,31],"blue":9},
+  {"expect":"2024002","time":"2024-01-04 21:15:00","openCode":"02,06,13,19,26,32+10"},
+  {"issueno":"2024003","opendate":"2024-01-07","number":"03 07 14 20 27 33","refernumber":"11"},
+  {"issue":"2024004","draw_date":"2024-01-09","reds":"04,08,15,21,28,33","blue":"12"}
+]`,
+			[]string{"2024001", "2024002", "2024003", "2024004"},
+			map[int]string{},
+		},
+		{
+			"array: rows are 1-based indexes",
+			"json",
+			`[{"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,31],"blue":9},
+ {"issue":"2024002","draw_date":"2024-01-04","reds":[1,5,12,18,25],"blue":9},
+ "not an object",
+ {"issue":"2024004","draw_date":"2024-01-09","openCode":"01,02,03,04,05,06"},
+ {"issue":"2024005","draw_date":"2024-01-11","reds":[1,5,12,18,25,31],"blue":9,"fetched_at":"yesterday"}]`,
+			[]string{"2024001"},
+			map[int]string{2: "", 3: "invalid json", 4: "missing '+'", 5: "invalid fetched_at"},
+		},
+		{
+			"jsonl: rows are file line numbers, blank lines skipped",
+			"jsonl",
+			`{"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,31],"blue":9}
+
+{"issue":"2024002","draw_date":"","reds":[1,5,12,18,25,31],"blue":9}
+{oops
+{"issue":"2024005","draw_date":"2024-01-11","reds":[1,5,12,18,25,31],"blue":17}
+{"issue":"2024006","draw_date":"2024-01-13","reds":[1,5,12,18,25,31],"blue":9,"source":"crawler"}`,
+			[]string{"2024001", "2024006"},
+			map[int]string{3: "empty date", 4: "invalid json", 5: ""},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rep := newImportReport(false)
+			rows, err := jsonRows([]byte(tc.body), tc.format, rep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range rows {
+				got = append(got, r.Draw.Issue)
+			}
+			if !slices.Equal(got, tc.accepted) {
+				t.Fatalf("accepted = %v, want %v", got, tc.accepted)
+			}
+			if rep.Rows != len(tc.accepted)+len(tc.rejected) {
+				t.Fatalf("rows = %d, want %d", rep.Rows, len(tc.accepted)+len(tc.rejected))
+			}
+			if len(rep.Rejected) != len(tc.rejected) {
+				t.Fatalf("rejected = %+v, want rows %v", rep.Rejected, tc.rejected)
+			}
+			for _, rj := range rep.Rejected {
+				want, ok := tc.rejected[rj.Row]
+				if !ok || !strings.Contains(rj.Reason, want) {
+					t.Errorf("rejected row %d (%s), want rows %v", rj.Row, rj.Reason, tc.rejected)
+				}
+			}
+		})
+	}
+
+	rep := newImportReport(false)
+	if _, err := jsonRows([]byte(`{"not":"an array"}`), "json", rep); err == nil {
+		t.Fatal("json format accepted a non-array body")
+	}
+}
+
+// 表格格式：被拒绝行的行号为文件中的行号（引号内换行、空行都计入）
+func TestImportFileCSVRejectedLines(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	file := writeFile(t, "draws.csv", "期号,日期,号码\n"+
+		"2024001,2024-01-02,\"01\n05\n12\n18\n25\n31\n09\"\n"+
+		"\n"+
+		"2024002,2024-01-04,\"02\n06\n13\"\n"+
+		"2024003,not-a-date,\"03\n07\n14\n20\n27\n33\n11\"\n"+
+		"2024004,2024-01-09,\"04\n08\n15\n21\n28\n33\n40\"\n")
+	rep, err := s.ImportFile(file, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Format != "csv" || rep.Columns == nil || rep.Columns.Codes != 2 {
+		t.Fatalf("format %s, columns %+v", rep.Format, rep.Columns)
+	}
+	var rows []int
+	for _, rj := range rep.Rejected {
+		rows = append(rows, rj.Row)
+	}
+	if rep.Rows != 4 || rep.Accepted != 1 || !slices.Equal(rows, []int{10, 13, 20}) {
+		t.Fatalf("rows %d accepted %d rejected %+v", rep.Rows, rep.Accepted, rep.Rejected)
+	}
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, body string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// dry-run 只报告将发生的变化，imported 恒为 0
+func TestMergeImportDryRunCounts(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	file := writeFile(t, "draws.json", `[
+  {"issue":"2024001","draw_date":"2024-01-02","reds":[1,5,12,18,25,31],"blue":9},
+  {"issue":"2024002","draw_date":"2024-01-04","reds":[2,6,13,19,26,32],"blue":10}
+]`)
+	rep, err := s.ImportFile(file, ImportOptions{Merge: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Imported != 0 || len(rep.Merge.Inserted) != 2 {
+		t.Fatalf("dry-run: imported %d, would insert %v", rep.Imported, rep.Merge.Inserted)
+	}
+	if d, _ := s.LatestDraw(); d != nil {
+		t.Fatalf("dry-run wrote %+v", d)
+	}
+
+	if rep, err = s.ImportFile(file, ImportOptions{Merge: true}); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Imported != 2 {
+		t.Fatalf("merge: imported %d, want 2", rep.Imported)
+	}
+
+	changed := writeFile(t, "draws.json", `[
+  {"issue":"2024002","draw_date":"2024-01-04","reds":[2,6,13,19,26,33],"blue":10},
+  {"issue":"2024003","draw_date":"2024-01-07","reds":[3,7,14,20,27,33],"blue":11}
+]`)
+	if rep, err = s.ImportFile(changed, ImportOptions{Merge: true, DryRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Imported != 0 || len(rep.Merge.Updated) != 1 || len(rep.Merge.Inserted) != 1 {
+		t.Fatalf("dry-run: imported %d, merge %+v", rep.Imported, rep.Merge)
+	}
+}
@@ -0,0 +1,324 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 库的 schema 版本高于本程序已知的最新版本（被更新的程序写过）；拒绝打开以免损坏数据
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+/* ----------------------------- 版本化迁移 ----------------------------- */
+
+// 每个迁移在独立事务内执行，成功后写入 schema_migrations。
+// 引入版本管理之前的库（无 schema_migrations，视为版本 0）会从 1 开始重放，
+// 因此早期迁移必须幂等：CREATE ... IF NOT EXISTS / addColumnIfMissing。
+// 只追加、不修改已发布的迁移。
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{1, "draws", execSQL(`
+CREATE TABLE IF NOT EXISTS draws (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  issue      TEXT UNIQUE,           -- 唯一期号（可为空；为空则不唯一）
+  draw_date  TEXT NOT NULL,         -- YYYY-MM-DD
+  reds       TEXT NOT NULL,         -- JSON 数组，如 "[1,2,3,4,5,6]"
+  blue       INTEGER NOT NULL,
+  source     TEXT,
+  fetched_at TEXT NOT NULL,         -- RFC3339
+  created_at TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%f','now'))
+);
+CREATE INDEX IF NOT EXISTS idx_draws_date ON draws(draw_date DESC, id DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_draws_issue ON draws(issue);
+`)},
+	{2, "slips", execSQL(`
+CREATE TABLE IF NOT EXISTS slips (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  name       TEXT NOT NULL,
+  issue      TEXT,                  -- 目标期号
+  config     TEXT,                  -- 生成时生效的 generator.Config（JSON）
+  seed       INTEGER NOT NULL DEFAULT 0,
+  created_at TEXT NOT NULL,         -- RFC3339
+  updated_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_slips_issue ON slips(issue);
+
+CREATE TABLE IF NOT EXISTS slip_tickets (
+  id      INTEGER PRIMARY KEY AUTOINCREMENT,
+  slip_id INTEGER NOT NULL REFERENCES slips(id) ON DELETE CASCADE,
+  idx     INTEGER NOT NULL,         -- 注序号（0 起）
+  reds    TEXT NOT NULL,            -- JSON 数组
+  blue    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_slip_tickets_slip ON slip_tickets(slip_id, idx);
+
+CREATE TABLE IF NOT EXISTS slip_results (
+  slip_id    INTEGER NOT NULL REFERENCES slips(id) ON DELETE CASCADE,
+  idx        INTEGER NOT NULL,
+  issue      TEXT NOT NULL,
+  red_hits   INTEGER NOT NULL,
+  blue_hit   INTEGER NOT NULL,      -- 0/1
+  tier       INTEGER NOT NULL,      -- 0 未中奖；1..6 一至六等奖
+  prize_yuan INTEGER NOT NULL,      -- 固定奖金；一/二等奖为浮动奖金记 0
+  checked_at TEXT NOT NULL,
+  PRIMARY KEY (slip_id, idx)
+);
+CREATE INDEX IF NOT EXISTS idx_slip_results_issue ON slip_results(issue);
+`)},
+	// 复式/胆拖
+	{3, "slip_ticket_types", addColumns(
+		column{"slip_tickets", "kind", "TEXT NOT NULL DEFAULT 'single'"},
+		column{"slip_tickets", "bankers", "TEXT"}, // JSON 数组
+		column{"slip_tickets", "blues", "TEXT"},   // JSON 数组
+		column{"slip_results", "bets", "INTEGER NOT NULL DEFAULT 1"},
+		column{"slip_results", "tier_counts", "TEXT"}, // JSON：{"奖级": 注数}
+	)},
+	{4, "draw_conflicts", execSQL(`
+CREATE TABLE IF NOT EXISTS draw_conflicts (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  issue      TEXT NOT NULL,
+  provider   TEXT NOT NULL,
+  draw_date  TEXT NOT NULL,
+  reds       TEXT NOT NULL,         -- JSON 数组（该源返回的号码）
+  blue       INTEGER NOT NULL,
+  reason     TEXT NOT NULL,         -- disagree | no_quorum
+  accepted   TEXT,                  -- 采纳的 Draw（JSON）；未达成法定数时为空
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_draw_conflicts_issue ON draw_conflicts(issue, id DESC);
+`)},
+	{5, "draw_audit", execSQL(`
+CREATE TABLE IF NOT EXISTS draw_audit (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  issue      TEXT NOT NULL,
+  action     TEXT NOT NULL,         -- insert | update | delete
+  old_value  TEXT,                  -- 修改前的 Draw（JSON）；insert 为空
+  new_value  TEXT,                  -- 修改后的 Draw（JSON）；delete 为空
+  actor      TEXT NOT NULL,
+  source     TEXT NOT NULL,         -- 变更渠道，如 api
+  remote     TEXT,                  -- 客户端地址
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_draw_audit_issue ON draw_audit(issue, id DESC);
+`)},
+	// 红球拆成 6 个整数列 + 规范组合键，频次/包含/重号统计直接走 SQL；读取也以 r1..r6 为准，reds（JSON）仅随写入保留
+	{6, "draw_red_columns", migrateRedColumns},
+	{7, "settings", execSQL(`
+CREATE TABLE IF NOT EXISTS settings (
+  key        TEXT PRIMARY KEY,
+  value      TEXT NOT NULL,         -- JSON
+  updated_at TEXT NOT NULL
+);
+`)},
+	{8, "config_presets", execSQL(`
+CREATE TABLE IF NOT EXISTS presets (
+  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+  name        TEXT NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  config      TEXT NOT NULL,        -- 完整的 generator.Config（JSON）
+  created_at  TEXT NOT NULL,
+  updated_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS preset_history (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  name       TEXT NOT NULL,         -- 按名称记录，删除后仍可查
+  action     TEXT NOT NULL,         -- create | update | delete
+  old_value  TEXT,                  -- 修改前的 Preset（JSON）；create 为空
+  new_value  TEXT,                  -- 修改后的 Preset（JSON）；delete 为空
+  actor      TEXT NOT NULL,
+  source     TEXT NOT NULL,
+  remote     TEXT,
+  created_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_preset_history_name ON preset_history(name, id DESC);
+`)},
+	// 同一源对同一期报出的同一组号码只记一次：先删重复（保留最早一条），再建唯一索引
+	{9, "draw_conflicts_unique", execSQL(`
+DELETE FROM draw_conflicts WHERE id NOT IN (
+  SELECT MIN(id) FROM draw_conflicts GROUP BY issue, provider, reds, blue
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_draw_conflicts_report ON draw_conflicts(issue, provider, reds, blue);
+`)},
+}
+
+// SchemaVersion：本程序支持的最新 schema 版本
+func SchemaVersion() int { return migrations[len(migrations)-1].version }
+
+func execSQL(stmts string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(stmts)
+		return err
+	}
+}
+
+type column struct{ table, name, decl string }
+
+func addColumns(cols ...column) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, c := range cols {
+			if err := addColumnIfMissing(tx, c.table, c.name, c.decl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func migrateRedColumns(tx *sql.Tx) error {
+	cols := []column{{"draws", "red_key", "TEXT"}} // "01,05,12,18,25,31"
+	for i := 1; i <= 6; i++ {
+		cols = append(cols, column{"draws", fmt.Sprintf("r%d", i), "INTEGER"})
+	}
+	if err := addColumns(cols...)(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+CREATE INDEX IF NOT EXISTS idx_draws_red_key ON draws(red_key);
+CREATE INDEX IF NOT EXISTS idx_draws_r1 ON draws(r1);
+CREATE INDEX IF NOT EXISTS idx_draws_r2 ON draws(r2);
+CREATE INDEX IF NOT EXISTS idx_draws_r3 ON draws(r3);
+CREATE INDEX IF NOT EXISTS idx_draws_r4 ON draws(r4);
+CREATE INDEX IF NOT EXISTS idx_draws_r5 ON draws(r5);
+CREATE INDEX IF NOT EXISTS idx_draws_r6 ON draws(r6);
+`); err != nil {
+		return err
+	}
+
+	// 转换已有行：旧数据的 reds 未必升序，按 Go 侧口径排序后回填（无法解析的行保持 NULL）
+	rows, err := tx.Query(`SELECT id, reds FROM draws`)
+	if err != nil {
+		return err
+	}
+	type conv struct {
+		id   int64
+		reds []int
+	}
+	var list []conv
+	for rows.Next() {
+		var c conv
+		var redsJSON string
+		if err := rows.Scan(&c.id, &redsJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		if json.Unmarshal([]byte(redsJSON), &c.reds) == nil && len(c.reds) == 6 {
+			sort.Ints(c.reds)
+			list = append(list, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`UPDATE draws SET reds=?, r1=?, r2=?, r3=?, r4=?, r5=?, r6=?, red_key=? WHERE id=?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, c := range list {
+		redsJSON, _ := json.Marshal(c.reds)
+		args := append([]any{string(redsJSON)}, redArgs(c.reds)...)
+		if _, err := stmt.Exec(append(args, c.id)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version    INTEGER PRIMARY KEY,
+  name       TEXT NOT NULL,
+  applied_at TEXT NOT NULL
+)`); err != nil {
+		return err
+	}
+	cur, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if latest := SchemaVersion(); cur > latest {
+		return fmt.Errorf("%w: database v%d, binary v%d", ErrSchemaTooNew, cur, latest)
+	}
+	for _, m := range migrations {
+		if m.version <= cur {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	if err = m.up(tx); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations(version, name, applied_at) VALUES(?,?,?)`,
+		m.version, m.name, time.Now().Format(time.RFC3339))
+	return err
+}
+
+// 已应用的最高版本；0 = 新库或引入版本管理之前的库
+func schemaVersion(q rowQuerier) (int, error) {
+	var v sql.NullInt64
+	if err := q.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&v); err != nil {
+		return 0, err
+	}
+	return int(v.Int64), nil
+}
+
+// SchemaVersion：当前库已应用的 schema 版本
+func (s *Store) SchemaVersion() (int, error) { return schemaVersion(s.db) }
+
+type queryExecer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func addColumnIfMissing(db queryExecer, table, col, decl string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	found := false
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		if strings.EqualFold(name, col) {
+			found = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || found {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, col, decl))
+	return err
+}
@@ -0,0 +1,173 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// 绕过 Open 直接打开库文件（不跑迁移），用于构造旧库/检查结果
+func rawDB(t *testing.T, dir string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open(driverName, dsn(filepath.Join(dir, "app.db"), connPragmas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func appliedVersions(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	rows, err := db.Query(`SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var out []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func allVersions() []int {
+	var out []int
+	for _, m := range migrations {
+		out = append(out, m.version)
+	}
+	return out
+}
+
+func TestMigrateEmptyDB(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if v, err := s.SchemaVersion(); err != nil || v != SchemaVersion() {
+		t.Fatalf("schema version = %d, %v; want %d", v, err, SchemaVersion())
+	}
+	if got := appliedVersions(t, s.db); !slices.Equal(got, allVersions()) {
+		t.Fatalf("applied = %v, want %v", got, allVersions())
+	}
+
+	// 再次打开不重复执行
+	s.Close()
+	s2, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	if got := appliedVersions(t, s2.db); !slices.Equal(got, allVersions()) {
+		t.Fatalf("reopen applied = %v, want %v", got, allVersions())
+	}
+}
+
+// v0：引入版本管理之前的库，只有最初的 draws 表，reds 未必升序
+func TestMigrateLegacyV0(t *testing.T) {
+	dir := t.TempDir()
+	legacy := rawDB(t, dir)
+	if _, err := legacy.Exec(`
+CREATE TABLE draws (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  issue      TEXT UNIQUE,
+  draw_date  TEXT NOT NULL,
+  reds       TEXT NOT NULL,
+  blue       INTEGER NOT NULL,
+  source     TEXT,
+  fetched_at TEXT NOT NULL,
+  created_at TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%f','now'))
+);
+INSERT INTO draws(issue, draw_date, reds, blue, source, fetched_at)
+VALUES ('2024001', '2024-01-02', '[31,5,18,1,25,12]', 9, 'manual', '2024-01-02T21:30:00Z');
+`); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got := appliedVersions(t, s.db); !slices.Equal(got, allVersions()) {
+		t.Fatalf("applied = %v, want %v", got, allVersions())
+	}
+
+	d, err := s.GetByIssue("2024001")
+	if err != nil || d == nil {
+		t.Fatalf("GetByIssue = %v, %v", d, err)
+	}
+	if want := []int{1, 5, 12, 18, 25, 31}; !slices.Equal(d.Reds, want) || d.Blue != 9 {
+		t.Fatalf("draw = %v+%d, want %v+9", d.Reds, d.Blue, want)
+	}
+	var key string
+	var r1, r6 int
+	if err := s.db.QueryRow(`SELECT red_key, r1, r6 FROM draws WHERE issue='2024001'`).Scan(&key, &r1, &r6); err != nil {
+		t.Fatal(err)
+	}
+	if key != "01,05,12,18,25,31" || r1 != 1 || r6 != 31 {
+		t.Fatalf("red columns = %q %d %d", key, r1, r6)
+	}
+}
+
+func TestMigrateRollsBackFailedMigration(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	boom := errors.New("boom")
+	next := SchemaVersion() + 1
+	migrations = append(slices.Clone(saved), migration{next, "broken", func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`CREATE TABLE half_done (id INTEGER)`); err != nil {
+			return err
+		}
+		return boom
+	}})
+
+	if _, err := Open(dir); !errors.Is(err, boom) {
+		t.Fatalf("Open err = %v, want %v", err, boom)
+	}
+	db := rawDB(t, dir)
+	if got := appliedVersions(t, db); !slices.Equal(got, allVersions()[:len(saved)]) {
+		t.Fatalf("applied = %v, want %v", got, allVersions()[:len(saved)])
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name='half_done'`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatal("failed migration left table half_done behind")
+	}
+}
+
+func TestMigrateSchemaTooNew(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO schema_migrations(version, name, applied_at) VALUES(?, 'future', '2030-01-01T00:00:00Z')`,
+		SchemaVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if _, err := Open(dir); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("Open err = %v, want ErrSchemaTooNew", err)
+	}
+}
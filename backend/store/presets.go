@@ -0,0 +1,245 @@
+package store
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrPresetExists   = errors.New("preset_exists")
+	ErrPresetNotFound = errors.New("preset_not_found")
+	ErrInvalidPreset  = errors.New("invalid_preset")
+)
+
+// 预设名称最长字符数
+const maxPresetName = 64
+
+/* ----------------------------- 配置预设 + 变更记录 ----------------------------- */
+
+// 命名的生成配置（策略档案）；配置内容由上层 generator 解释，这里只做存取
+type Preset struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Config      json.RawMessage `json:"config"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type PresetChange struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Action    string    `json:"action"` // create | update | delete
+	Old       *Preset   `json:"old,omitempty"`
+	New       *Preset   `json:"new,omitempty"`
+	Actor     string    `json:"actor"`
+	Source    string    `json:"source"`
+	Remote    string    `json:"remote,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatePreset：新建预设；名称已存在返回 ErrPresetExists
+func (s *Store) CreatePreset(p Preset, meta AuditMeta) (*Preset, error) {
+	return s.changePreset("create", p.Name, &p, meta)
+}
+
+// UpdatePreset：整体替换描述与配置；不存在返回 ErrPresetNotFound。内容未变时不写记录
+func (s *Store) UpdatePreset(name string, p Preset, meta AuditMeta) (*Preset, error) {
+	return s.changePreset("update", name, &p, meta)
+}
+
+// DeletePreset：删除预设；返回被删除的旧值（变更记录保留）
+func (s *Store) DeletePreset(name string, meta AuditMeta) (*Preset, error) {
+	return s.changePreset("delete", name, nil, meta)
+}
+
+// 变更与记录在同一事务内
+func (s *Store) changePreset(action, name string, in *Preset, meta AuditMeta) (out *Preset, err error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxPresetName || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: name must be 1..%d characters without '/'", ErrInvalidPreset, maxPresetName)
+	}
+	now := time.Now()
+	var next Preset
+	if in != nil {
+		if len(in.Config) == 0 || !json.Valid(in.Config) {
+			return nil, fmt.Errorf("%w: config must be valid JSON", ErrInvalidPreset)
+		}
+		next = Preset{Name: name, Description: strings.TrimSpace(in.Description), Config: in.Config,
+			CreatedAt: now, UpdatedAt: now}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	prev, err := getPreset(tx, name)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case action == "create" && prev != nil:
+		return nil, ErrPresetExists
+	case action != "create" && prev == nil:
+		return nil, ErrPresetNotFound
+	}
+
+	ts := now.Format(time.RFC3339Nano)
+	switch action {
+	case "create":
+		_, err = tx.Exec(`INSERT INTO presets(name, description, config, created_at, updated_at) VALUES(?,?,?,?,?)`,
+			name, next.Description, string(next.Config), ts, ts)
+		out = &next
+	case "update":
+		if prev.Description == next.Description && jsonEqual(prev.Config, next.Config) {
+			return prev, nil
+		}
+		next.CreatedAt = prev.CreatedAt
+		_, err = tx.Exec(`UPDATE presets SET description=?, config=?, updated_at=? WHERE name=?`,
+			next.Description, string(next.Config), ts, name)
+		out = &next
+	case "delete":
+		_, err = tx.Exec(`DELETE FROM presets WHERE name=?`, name)
+		out = prev
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var newVal *Preset
+	if in != nil {
+		newVal = &next
+	}
+	_, err = tx.Exec(`INSERT INTO preset_history(name, action, old_value, new_value, actor, source, remote, created_at)
+VALUES(?,?,?,?,?,?,?,?)`, name, action, presetJSON(prev), presetJSON(newVal),
+		meta.Actor, meta.Source, nullIfEmpty(meta.Remote), ts)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPreset：不存在返回 (nil, nil)
+func (s *Store) GetPreset(name string) (*Preset, error) {
+	return getPreset(s.db, strings.TrimSpace(name))
+}
+
+func getPreset(q rowQuerier, name string) (*Preset, error) {
+	p, err := scanPreset(q.QueryRow(`SELECT name, description, config, created_at, updated_at
+FROM presets WHERE name=?`, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// ListPresets：按名称排序
+func (s *Store) ListPresets() ([]Preset, error) {
+	rows, err := s.db.Query(`SELECT name, description, config, created_at, updated_at FROM presets ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Preset{}
+	for rows.Next() {
+		p, err := scanPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+func scanPreset(row rowScanner) (*Preset, error) {
+	var p Preset
+	var config, created, updated string
+	if err := row.Scan(&p.Name, &p.Description, &config, &created, &updated); err != nil {
+		return nil, err
+	}
+	p.Config = json.RawMessage(config)
+	if t, e := parseTimeFlexible(created); e == nil {
+		p.CreatedAt = t
+	}
+	if t, e := parseTimeFlexible(updated); e == nil {
+		p.UpdatedAt = t
+	}
+	return &p, nil
+}
+
+// ListPresetHistory：某预设的变更记录，按时间倒序；limit<=0 不限
+func (s *Store) ListPresetHistory(name string, limit int) ([]PresetChange, error) {
+	q := `SELECT id, name, action, old_value, new_value, actor, source, remote, created_at
+FROM preset_history WHERE name=? ORDER BY id DESC`
+	args := []any{strings.TrimSpace(name)}
+	if limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []PresetChange{}
+	for rows.Next() {
+		var c PresetChange
+		var oldVal, newVal, remote *string
+		var created string
+		if err := rows.Scan(&c.ID, &c.Name, &c.Action, &oldVal, &newVal, &c.Actor, &c.Source, &remote, &created); err != nil {
+			return nil, err
+		}
+		c.Old, c.New = decodePreset(oldVal), decodePreset(newVal)
+		if remote != nil {
+			c.Remote = *remote
+		}
+		if t, e := parseTimeFlexible(created); e == nil {
+			c.CreatedAt = t
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func presetJSON(p *Preset) any {
+	if p == nil {
+		return nil
+	}
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func decodePreset(raw *string) *Preset {
+	if raw == nil {
+		return nil
+	}
+	var p Preset
+	if json.Unmarshal([]byte(*raw), &p) != nil {
+		return nil
+	}
+	return &p
+}
+
+// 忽略空白差异比较两段 JSON
+func jsonEqual(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return false
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 查询参数无效（游标、取值越界）；调用方据此返回 400
+var ErrBadQuery = errors.New("invalid query")
+
+// 分页上限
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+/* ----------------------------- 分页查询 ----------------------------- */
+
+// 过滤条件均为可选（零值 = 不限）；区间含端点
+type DrawQuery struct {
+	Cursor string // 上一页返回的 next_cursor
+	Limit  int    // <=0 → DefaultPageSize；上限 MaxPageSize
+	Asc    bool   // 默认按 (draw_date, id) 倒序（最新在前）
+
+	FromIssue, ToIssue string
+	FromDate, ToDate   string
+	Source             string
+	Contains           []int // 红球须全部包含
+	Blue               int
+	MinSum, MaxSum     int // 红球和值
+}
+
+type DrawPage struct {
+	Draws      []Draw `json:"draws"`
+	NextCursor string `json:"next_cursor,omitempty"` // 空 = 没有下一页
+}
+
+// 游标为 (draw_date, id) 的不透明编码，对应 idx_draws_date 索引
+func encodeCursor(date string, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(date + "|" + strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(c string) (string, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: bad cursor", ErrBadQuery)
+	}
+	date, idStr, ok := strings.Cut(string(raw), "|")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if !ok || err != nil {
+		return "", 0, fmt.Errorf("%w: bad cursor", ErrBadQuery)
+	}
+	return date, id, nil
+}
+
+// QueryDraws：按 (draw_date, id) 游标分页；游标与排序方向需一致
+func (s *Store) QueryDraws(q DrawQuery) (*DrawPage, error) {
+	var where []string
+	var args []any
+	add := func(cond string, a ...any) {
+		where = append(where, cond)
+		args = append(args, a...)
+	}
+
+	if q.Cursor != "" {
+		date, id, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if q.Asc {
+			add(`(draw_date > ? OR (draw_date = ? AND id > ?))`, date, date, id)
+		} else {
+			add(`(draw_date < ? OR (draw_date = ? AND id < ?))`, date, date, id)
+		}
+	}
+	if q.FromIssue != "" {
+		add(`issue >= ?`, q.FromIssue)
+	}
+	if q.ToIssue != "" {
+		add(`issue <= ?`, q.ToIssue)
+	}
+	if q.FromDate != "" {
+		add(`draw_date >= ?`, NormalizeDate(q.FromDate))
+	}
+	if q.ToDate != "" {
+		add(`draw_date <= ?`, NormalizeDate(q.ToDate))
+	}
+	if q.Source != "" {
+		add(`source = ?`, q.Source)
+	}
+	for _, n := range q.Contains {
+		if n < 1 || n > 33 {
+			return nil, fmt.Errorf("%w: contains: red out of range: %d", ErrBadQuery, n)
+		}
+		add(`? IN (r1, r2, r3, r4, r5, r6)`, n)
+	}
+	if q.Blue != 0 {
+		if q.Blue < 1 || q.Blue > 16 {
+			return nil, fmt.Errorf("%w: blue out of range: %d", ErrBadQuery, q.Blue)
+		}
+		add(`blue = ?`, q.Blue)
+	}
+	if q.MinSum > 0 {
+		add(`(r1+r2+r3+r4+r5+r6) >= ?`, q.MinSum)
+	}
+	if q.MaxSum > 0 {
+		add(`(r1+r2+r3+r4+r5+r6) <= ?`, q.MaxSum)
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	query := `SELECT id, ` + drawCols + ` FROM draws`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	if q.Asc {
+		query += ` ORDER BY draw_date ASC, id ASC`
+	} else {
+		query += ` ORDER BY draw_date DESC, id DESC`
+	}
+	query += ` LIMIT ?`
+	args = append(args, limit+1) // 多取一行判断是否有下一页
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &DrawPage{Draws: []Draw{}}
+	var lastID int64
+	for rows.Next() {
+		var r drawRow
+		var id int64
+		if err := rows.Scan(append([]any{&id}, r.dest()...)...); err != nil {
+			return nil, err
+		}
+		if len(page.Draws) == limit {
+			page.NextCursor = encodeCursor(page.Draws[limit-1].DrawDate, lastID)
+			break
+		}
+		page.Draws = append(page.Draws, r.draw())
+		lastID = id
+	}
+	return page, rows.Err()
+}
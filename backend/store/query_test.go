@@ -0,0 +1,156 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// 按插入顺序（即 id 顺序）写入；同一日期下期号顺序与 id 顺序故意不一致
+func queryFixture(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	rows := []struct{ issue, date, source string }{
+		{"2024003", "2024-01-02", "crawler"},
+		{"2024001", "2024-01-02", "crawler"},
+		{"2024002", "2024-01-02", "crawler"},
+		{"2024004", "2024-01-04", "manual"},
+		{"2024006", "2024-01-04", "crawler"},
+		{"2024005", "2024-01-04", "crawler"},
+		{"2024007", "2024-01-07", "crawler"},
+		{"2024008", "2024-01-09", "crawler"},
+	}
+	for i, r := range rows {
+		n := i + 1 // 红球和值 = 2n+118，蓝球 = n
+		d := Draw{Issue: r.issue, DrawDate: r.date, Reds: []int{n, 10 + n, 20, 25, 30, 33}, Blue: n, Source: r.source}
+		if err := s.UpsertDrawByIssue(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// 逐页取完，返回期号序列与页数
+func queryAll(t *testing.T, s *Store, q DrawQuery) ([]string, int) {
+	t.Helper()
+	var issues []string
+	pages := 0
+	for {
+		page, err := s.QueryDraws(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		if len(page.Draws) > q.Limit {
+			t.Fatalf("page %d has %d rows, limit %d", pages, len(page.Draws), q.Limit)
+		}
+		for _, d := range page.Draws {
+			issues = append(issues, d.Issue)
+		}
+		if page.NextCursor == "" {
+			return issues, pages
+		}
+		if pages > 20 {
+			t.Fatal("cursor does not advance")
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func TestQueryDrawsCursorPaging(t *testing.T) {
+	s := queryFixture(t)
+	// (draw_date, id) 升序：同日按 id，而不是按期号
+	asc := []string{"2024003", "2024001", "2024002", "2024004", "2024006", "2024005", "2024007", "2024008"}
+	desc := slices.Clone(asc)
+	slices.Reverse(desc)
+
+	for _, limit := range []int{1, 2, 3, 4, 8, 50} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			wantPages := (len(asc) + limit - 1) / limit
+			got, pages := queryAll(t, s, DrawQuery{Limit: limit, Asc: true})
+			if !slices.Equal(got, asc) || pages != wantPages {
+				t.Fatalf("asc = %v in %d pages, want %v in %d", got, pages, asc, wantPages)
+			}
+			got, pages = queryAll(t, s, DrawQuery{Limit: limit})
+			if !slices.Equal(got, desc) || pages != wantPages {
+				t.Fatalf("desc = %v in %d pages, want %v in %d", got, pages, desc, wantPages)
+			}
+		})
+	}
+
+	// 同一游标重复请求结果稳定
+	first, err := s.QueryDraws(DrawQuery{Limit: 2, Asc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again []string
+	for range 3 {
+		page, err := s.QueryDraws(DrawQuery{Limit: 2, Asc: true, Cursor: first.NextCursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var issues []string
+		for _, d := range page.Draws {
+			issues = append(issues, d.Issue)
+		}
+		if again != nil && !slices.Equal(issues, again) {
+			t.Fatalf("same cursor gave %v then %v", again, issues)
+		}
+		again = issues
+	}
+	if !slices.Equal(again, []string{"2024002", "2024004"}) {
+		t.Fatalf("second page = %v", again)
+	}
+}
+
+func TestQueryDrawsFilters(t *testing.T) {
+	s := queryFixture(t)
+	cases := []struct {
+		name string
+		q    DrawQuery
+		want []string
+	}{
+		{"source", DrawQuery{Source: "manual"}, []string{"2024004"}},
+		{"date range, slash date", DrawQuery{FromDate: "2024-01-04", ToDate: "2024/01/07", Asc: true},
+			[]string{"2024004", "2024006", "2024005", "2024007"}},
+		{"issue range", DrawQuery{FromIssue: "2024002", ToIssue: "2024005"},
+			[]string{"2024005", "2024004", "2024002", "2024003"}},
+		{"contains one red", DrawQuery{Contains: []int{13}}, []string{"2024002"}},
+		{"contains all", DrawQuery{Contains: []int{20, 33}, FromDate: "2024-01-07"}, []string{"2024008", "2024007"}},
+		{"blue", DrawQuery{Blue: 5}, []string{"2024006"}},
+		{"sum range", DrawQuery{MinSum: 128, MaxSum: 130, Asc: true}, []string{"2024006", "2024005"}},
+		{"combined, nothing matches", DrawQuery{Source: "manual", Blue: 1}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// 每页 1 行：过滤条件在翻页时同样生效
+			tc.q.Limit = 1
+			got, _ := queryAll(t, s, tc.q)
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	bad := []DrawQuery{
+		{Cursor: "!!!"},
+		{Cursor: encodeCursor("2024-01-02", 0)[:3]},
+		{Contains: []int{34}},
+		{Blue: 17},
+	}
+	for _, q := range bad {
+		if _, err := s.QueryDraws(q); !errors.Is(err, ErrBadQuery) {
+			t.Errorf("QueryDraws(%+v): err = %v, want ErrBadQuery", q, err)
+		}
+	}
+
+	page, err := s.QueryDraws(DrawQuery{Limit: MaxPageSize + 100})
+	if err != nil || len(page.Draws) != 8 || page.NextCursor != "" {
+		t.Fatalf("oversized limit: %d rows, cursor %q, %v", len(page.Draws), page.NextCursor, err)
+	}
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+// 读取以 r1..r6 为准：reds（JSON）与列不一致时不影响读出的号码
+func TestReadsUseRedColumns(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	want := []int{3, 9, 14, 20, 27, 31}
+	if err := s.UpsertDrawByIssue(Draw{Issue: "2024098", DrawDate: "2024-08-25", Reds: want, Blue: 12}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.Exec(`UPDATE draws SET reds='[1,2,3,4,5,6]' WHERE issue='2024098'`); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, d *Draw) {
+		t.Helper()
+		if d == nil || !slices.Equal(d.Reds, want) || d.Blue != 12 || d.Issue != "2024098" {
+			t.Errorf("%s = %+v, want reds %v", name, d, want)
+		}
+	}
+	d, err := s.GetByIssue("2024098")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("GetByIssue", d)
+	if d, err = s.LatestDraw(); err != nil {
+		t.Fatal(err)
+	}
+	check("LatestDraw", d)
+	list, err := s.ListRecentDraws(0)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListRecentDraws = %v, %v", list, err)
+	}
+	check("ListRecentDraws", &list[0])
+	page, err := s.QueryDraws(DrawQuery{})
+	if err != nil || len(page.Draws) != 1 {
+		t.Fatalf("QueryDraws = %v, %v", page, err)
+	}
+	check("QueryDraws", &page.Draws[0])
+
+	// 迁移时无法解析的旧行：r1..r6 为 NULL，读出空号码而不是报错
+	if _, err := s.db.Exec(`UPDATE draws SET r1=NULL, r2=NULL, r3=NULL, r4=NULL, r5=NULL, r6=NULL, red_key=NULL`); err != nil {
+		t.Fatal(err)
+	}
+	if d, err = s.GetByIssue("2024098"); err != nil || d == nil || d.Reds != nil {
+		t.Fatalf("GetByIssue on NULL columns = %+v, %v", d, err)
+	}
+}
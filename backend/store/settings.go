@@ -0,0 +1,34 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+/* ----------------------------- 服务端设置 ----------------------------- */
+
+// GetSetting：读取 key 对应的 JSON；不存在返回 (nil, nil)
+func (s *Store) GetSetting(key string) (json.RawMessage, error) {
+	var raw string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key=?`, key).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(raw), nil
+}
+
+// PutSetting：以 JSON 保存 value（覆盖旧值）
+func (s *Store) PutSetting(key string, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO settings(key, value, updated_at) VALUES(?,?,?)
+ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=excluded.updated_at`,
+		key, string(b), time.Now().Format(time.RFC3339))
+	return err
+}
@@ -0,0 +1,405 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrSlipNotFound = errors.New("slip_not_found")
+
+// 一张票：单式（6 红 + 1 蓝）、复式或胆拖。注数/规则由 generator.Ticket 负责，这里只做存取
+type Ticket struct {
+	Kind    string `json:"type,omitempty"`    // single/multiple/banker；空视为 single
+	Reds    []int  `json:"reds"`              // 升序；胆拖时为拖码
+	Bankers []int  `json:"bankers,omitempty"` // 胆拖：胆码
+	Blue    int    `json:"blue"`              // 首个蓝球（兼容单式写法）
+	Blues   []int  `json:"blues,omitempty"`
+}
+
+// 一次生成/购买记录：绑定目标期号，保存生成时实际生效的配置与种子
+type Slip struct {
+	ID        int64           `json:"id"`
+	Name      string          `json:"name"`
+	Issue     string          `json:"issue"`            // 目标期号，如 "2024099"
+	Config    json.RawMessage `json:"config,omitempty"` // generator.Config（JSON 原样保存）
+	Seed      int64           `json:"seed"`
+	Tickets   []Ticket        `json:"tickets"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+/* ------------------------------- 写入 ------------------------------- */
+
+// CreateSlip：写入 slip 及其全部注；成功后回填 ID 与时间戳
+func (s *Store) CreateSlip(sl *Slip) error {
+	tickets, err := normalizeTickets(sl.Tickets)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.Exec(`
+INSERT INTO slips(name, issue, config, seed, created_at, updated_at)
+VALUES(?,?,?,?,?,?)
+`, strings.TrimSpace(sl.Name), nullIfEmpty(sl.Issue), nullIfEmptyJSON(sl.Config), sl.Seed,
+		now.Format(time.RFC3339Nano), now.Format(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err = insertTickets(tx, id, tickets); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	sl.ID = id
+	sl.Issue = strings.TrimSpace(sl.Issue)
+	sl.Tickets = tickets
+	sl.CreatedAt, sl.UpdatedAt = now, now
+	return nil
+}
+
+// UpdateSlip：更新名称/期号；Tickets 非 nil 时整体替换注列表
+func (s *Store) UpdateSlip(sl Slip) error {
+	var tickets []Ticket
+	if sl.Tickets != nil {
+		var err error
+		if tickets, err = normalizeTickets(sl.Tickets); err != nil {
+			return err
+		}
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.Exec(`UPDATE slips SET name=?, issue=?, updated_at=? WHERE id=?`,
+		strings.TrimSpace(sl.Name), nullIfEmpty(sl.Issue), time.Now().Format(time.RFC3339Nano), sl.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = ErrSlipNotFound
+		return err
+	}
+	// 号码或期号可能已变，旧的兑奖结果作废
+	if _, err = tx.Exec(`DELETE FROM slip_results WHERE slip_id=?`, sl.ID); err != nil {
+		return err
+	}
+	if sl.Tickets != nil {
+		if _, err = tx.Exec(`DELETE FROM slip_tickets WHERE slip_id=?`, sl.ID); err != nil {
+			return err
+		}
+		if err = insertTickets(tx, sl.ID, tickets); err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	return err
+}
+
+func (s *Store) DeleteSlip(id int64) error {
+	res, err := s.db.Exec(`DELETE FROM slips WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSlipNotFound
+	}
+	return nil
+}
+
+func insertTickets(tx *sql.Tx, slipID int64, tickets []Ticket) error {
+	stmt, err := tx.Prepare(`
+INSERT INTO slip_tickets(slip_id, idx, kind, reds, bankers, blue, blues)
+VALUES(?,?,?,?,?,?,?)
+`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for i, t := range tickets {
+		redsJSON, _ := json.Marshal(t.Reds)
+		bankersJSON, _ := json.Marshal(t.Bankers)
+		bluesJSON, _ := json.Marshal(t.Blues)
+		if _, err := stmt.Exec(slipID, i, t.Kind, string(redsJSON), string(bankersJSON), t.Blue, string(bluesJSON)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* ------------------------------- 查询 ------------------------------- */
+
+// GetSlip：不存在返回 (nil, nil)
+func (s *Store) GetSlip(id int64) (*Slip, error) {
+	row := s.db.QueryRow(`SELECT id, name, issue, config, seed, created_at, updated_at
+FROM slips WHERE id=?`, id)
+	sl, err := scanSlip(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if sl.Tickets, err = s.slipTickets(sl.ID); err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+// ListSlips：issue 为空 → 全部；否则仅该期。按创建时间倒序
+func (s *Store) ListSlips(issue string) ([]Slip, error) {
+	q := `SELECT id, name, issue, config, seed, created_at, updated_at FROM slips`
+	var args []any
+	if issue = strings.TrimSpace(issue); issue != "" {
+		q += ` WHERE issue=?`
+		args = append(args, issue)
+	}
+	q += ` ORDER BY id DESC`
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Slip{}
+	for rows.Next() {
+		sl, err := scanSlip(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *sl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].Tickets, err = s.slipTickets(list[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func (s *Store) slipTickets(slipID int64) ([]Ticket, error) {
+	rows, err := s.db.Query(`SELECT kind, reds, bankers, blue, blues
+FROM slip_tickets WHERE slip_id=? ORDER BY idx ASC`, slipID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Ticket{}
+	for rows.Next() {
+		var t Ticket
+		var redsJSON string
+		var bankersJSON, bluesJSON sql.NullString
+		if err := rows.Scan(&t.Kind, &redsJSON, &bankersJSON, &t.Blue, &bluesJSON); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(redsJSON), &t.Reds)
+		if bankersJSON.Valid {
+			_ = json.Unmarshal([]byte(bankersJSON.String), &t.Bankers)
+		}
+		if bluesJSON.Valid {
+			_ = json.Unmarshal([]byte(bluesJSON.String), &t.Blues)
+		}
+		if len(t.Blues) == 0 {
+			t.Blues = []int{t.Blue}
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+type rowScanner interface{ Scan(dest ...any) error }
+
+func scanSlip(row rowScanner) (*Slip, error) {
+	var sl Slip
+	var issue, config sql.NullString
+	var created, updated string
+	if err := row.Scan(&sl.ID, &sl.Name, &issue, &config, &sl.Seed, &created, &updated); err != nil {
+		return nil, err
+	}
+	sl.Issue = issue.String
+	if config.Valid && config.String != "" {
+		sl.Config = json.RawMessage(config.String)
+	}
+	if t, e := parseTimeFlexible(created); e == nil {
+		sl.CreatedAt = t
+	}
+	if t, e := parseTimeFlexible(updated); e == nil {
+		sl.UpdatedAt = t
+	}
+	return &sl, nil
+}
+
+/* ------------------------------- utils ------------------------------- */
+
+func normalizeTickets(in []Ticket) ([]Ticket, error) {
+	if len(in) == 0 {
+		return nil, errors.New("tickets required")
+	}
+	out := make([]Ticket, 0, len(in))
+	for i, t := range in {
+		kind := strings.TrimSpace(t.Kind)
+		if kind == "" {
+			kind = "single"
+		}
+		blues := t.Blues
+		if len(blues) == 0 && t.Blue > 0 {
+			blues = []int{t.Blue}
+		}
+		if kind == "single" && (len(t.Reds) != 6 || len(blues) != 1) {
+			return nil, fmt.Errorf("ticket %d: single ticket must be 6 reds + 1 blue", i)
+		}
+		if len(t.Reds) == 0 || len(blues) == 0 {
+			return nil, fmt.Errorf("ticket %d: reds and blues required", i)
+		}
+		reds, err := sortedUnique(t.Reds, 1, 33)
+		if err != nil {
+			return nil, fmt.Errorf("ticket %d: red %w", i, err)
+		}
+		bankers, err := sortedUnique(t.Bankers, 1, 33)
+		if err != nil {
+			return nil, fmt.Errorf("ticket %d: banker %w", i, err)
+		}
+		if blues, err = sortedUnique(blues, 1, 16); err != nil {
+			return nil, fmt.Errorf("ticket %d: blue %w", i, err)
+		}
+		out = append(out, Ticket{Kind: kind, Reds: reds, Bankers: bankers, Blue: blues[0], Blues: blues})
+	}
+	return out, nil
+}
+
+func sortedUnique(a []int, lo, hi int) ([]int, error) {
+	cp := append([]int(nil), a...)
+	sort.Ints(cp)
+	for j, v := range cp {
+		if v < lo || v > hi {
+			return nil, fmt.Errorf("out of range: %d", v)
+		}
+		if j > 0 && v == cp[j-1] {
+			return nil, fmt.Errorf("duplicate: %d", v)
+		}
+	}
+	return cp, nil
+}
+
+func nullIfEmptyJSON(raw json.RawMessage) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return string(raw)
+}
+
+/* ------------------------------ 兑奖结果 ------------------------------ */
+
+// 单注兑奖结果（奖级语义由上层 prize 包决定，这里只做存取）
+type TicketResult struct {
+	SlipID     int64       `json:"slip_id"`
+	Idx        int         `json:"idx"`
+	Issue      string      `json:"issue"`
+	RedHits    int         `json:"red_hits"`    // 最佳一注的红球命中数
+	BlueHit    bool        `json:"blue_hit"`    // 任一蓝球命中
+	Tier       int         `json:"tier"`        // 最佳奖级：0=未中奖；1..6
+	PrizeYuan  int         `json:"prize_yuan"`  // 全部中奖注的固定奖金；浮动奖级记 0
+	Bets       int         `json:"bets"`        // 该票注数
+	TierCounts map[int]int `json:"tier_counts"` // 各奖级中奖注数
+	CheckedAt  time.Time   `json:"checked_at"`
+}
+
+// SaveSlipResults：整体替换某 slip 的兑奖结果
+func (s *Store) SaveSlipResults(slipID int64, rs []TicketResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.Exec(`DELETE FROM slip_results WHERE slip_id=?`, slipID); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`
+INSERT INTO slip_results(slip_id, idx, issue, red_hits, blue_hit, tier, prize_yuan, bets, tier_counts, checked_at)
+VALUES(?,?,?,?,?,?,?,?,?,?)
+`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	now := time.Now().Format(time.RFC3339Nano)
+	for _, r := range rs {
+		countsJSON, _ := json.Marshal(r.TierCounts)
+		if _, err = stmt.Exec(slipID, r.Idx, r.Issue, r.RedHits, r.BlueHit, r.Tier, r.PrizeYuan,
+			max(1, r.Bets), string(countsJSON), now); err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	return err
+}
+
+func (s *Store) SlipResults(slipID int64) ([]TicketResult, error) {
+	return s.queryResults(`WHERE slip_id=? ORDER BY idx ASC`, slipID)
+}
+
+func (s *Store) IssueResults(issue string) ([]TicketResult, error) {
+	return s.queryResults(`WHERE issue=? ORDER BY slip_id ASC, idx ASC`, strings.TrimSpace(issue))
+}
+
+func (s *Store) queryResults(where string, args ...any) ([]TicketResult, error) {
+	rows, err := s.db.Query(`SELECT slip_id, idx, issue, red_hits, blue_hit, tier, prize_yuan, bets, tier_counts, checked_at
+FROM slip_results `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []TicketResult{}
+	for rows.Next() {
+		var r TicketResult
+		var checked string
+		var countsJSON sql.NullString
+		if err := rows.Scan(&r.SlipID, &r.Idx, &r.Issue, &r.RedHits, &r.BlueHit, &r.Tier, &r.PrizeYuan,
+			&r.Bets, &countsJSON, &checked); err != nil {
+			return nil, err
+		}
+		r.TierCounts = map[int]int{}
+		if countsJSON.Valid {
+			_ = json.Unmarshal([]byte(countsJSON.String), &r.TierCounts)
+		} else if r.Tier > 0 {
+			r.TierCounts[r.Tier] = 1 // 旧数据：单式一注
+		}
+		if t, e := parseTimeFlexible(checked); e == nil {
+			r.CheckedAt = t
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
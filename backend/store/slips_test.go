@@ -0,0 +1,121 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSlipCRUD(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.CreateSlip(&Slip{Issue: "2024001"}); err == nil {
+		t.Fatal("created a slip without tickets")
+	}
+
+	a := Slip{
+		Name:   " 第一张 ",
+		Issue:  "2024001",
+		Config: json.RawMessage(`{"generate_count":2}`),
+		Seed:   42,
+		Tickets: []Ticket{
+			{Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9},
+			{Kind: "multiple", Reds: []int{1, 2, 3, 4, 5, 6, 7}, Blues: []int{9, 10}},
+		},
+	}
+	if err := s.CreateSlip(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a.ID == 0 || a.CreatedAt.IsZero() {
+		t.Fatalf("created slip = %+v", a)
+	}
+	b := Slip{Name: "第二张", Issue: "2024002", Tickets: []Ticket{{Reds: []int{2, 6, 13, 19, 26, 32}, Blue: 10}}}
+	if err := s.CreateSlip(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetSlip(a.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetSlip = %+v, %v", got, err)
+	}
+	wantTickets := []Ticket{
+		{Kind: "single", Reds: []int{1, 5, 12, 18, 25, 31}, Blue: 9, Blues: []int{9}},
+		{Kind: "multiple", Reds: []int{1, 2, 3, 4, 5, 6, 7}, Blue: 9, Blues: []int{9, 10}},
+	}
+	if got.Name != "第一张" || got.Issue != "2024001" || got.Seed != 42 || string(got.Config) != `{"generate_count":2}` ||
+		!reflect.DeepEqual(got.Tickets, wantTickets) {
+		t.Fatalf("GetSlip = %+v", got)
+	}
+	if got, err := s.GetSlip(999); got != nil || err != nil {
+		t.Fatalf("missing slip = %+v, %v", got, err)
+	}
+
+	all, err := s.ListSlips("")
+	if err != nil || len(all) != 2 || all[0].ID != b.ID || all[1].ID != a.ID {
+		t.Fatalf("ListSlips(all) = %+v, %v", all, err)
+	}
+	one, err := s.ListSlips(" 2024001 ")
+	if err != nil || len(one) != 1 || one[0].ID != a.ID || len(one[0].Tickets) != 2 {
+		t.Fatalf("ListSlips(2024001) = %+v, %v", one, err)
+	}
+
+	// 改名/期号、Tickets 为 nil：号码不变，已存兑奖结果作废
+	if err := s.SaveSlipResults(a.ID, []TicketResult{{Issue: "2024001", Tier: 6, PrizeYuan: 5, Bets: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateSlip(Slip{ID: a.ID, Name: "改名", Issue: "2024003"}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetSlip(a.ID)
+	if got.Name != "改名" || got.Issue != "2024003" || !reflect.DeepEqual(got.Tickets, wantTickets) {
+		t.Fatalf("after rename = %+v", got)
+	}
+	if rs, err := s.SlipResults(a.ID); err != nil || len(rs) != 0 {
+		t.Fatalf("results after update = %+v, %v", rs, err)
+	}
+
+	// 显式空列表被拒绝，原号码保留
+	if err := s.UpdateSlip(Slip{ID: a.ID, Name: "改名", Issue: "2024003", Tickets: []Ticket{}}); err == nil {
+		t.Fatal("update with empty tickets accepted")
+	}
+	if got, _ = s.GetSlip(a.ID); len(got.Tickets) != 2 {
+		t.Fatalf("tickets after rejected update = %+v", got.Tickets)
+	}
+
+	// 给出 tickets：整体替换
+	if err := s.UpdateSlip(Slip{ID: a.ID, Name: "改名", Issue: "2024003", Tickets: []Ticket{{Reds: []int{3, 7, 14, 20, 27, 33}, Blue: 11}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = s.GetSlip(a.ID); len(got.Tickets) != 1 || got.Tickets[0].Blue != 11 {
+		t.Fatalf("tickets after replace = %+v", got.Tickets)
+	}
+	if err := s.UpdateSlip(Slip{ID: 999, Tickets: []Ticket{{Reds: []int{1, 2, 3, 4, 5, 6}, Blue: 1}}}); !errors.Is(err, ErrSlipNotFound) {
+		t.Fatalf("update missing: err = %v", err)
+	}
+
+	// 删除：注与兑奖结果一并删除
+	if err := s.SaveSlipResults(b.ID, []TicketResult{{Issue: "2024002", Bets: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteSlip(b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetSlip(b.ID); got != nil || err != nil {
+		t.Fatalf("deleted slip = %+v, %v", got, err)
+	}
+	if rs, err := s.SlipResults(b.ID); err != nil || len(rs) != 0 {
+		t.Fatalf("results after delete = %+v, %v", rs, err)
+	}
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM slip_tickets WHERE slip_id=?`, b.ID).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("tickets after delete = %d, %v", n, err)
+	}
+	if err := s.DeleteSlip(b.ID); !errors.Is(err, ErrSlipNotFound) {
+		t.Fatalf("delete twice: err = %v", err)
+	}
+}
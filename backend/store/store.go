@@ -8,11 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3" // 若需无 CGO：改为 _ "modernc.org/sqlite"
-	"github.com/xuri/excelize/v2"
 )
 
 var ErrAlreadyInitialized = errors.New("already_initialized")
@@ -37,6 +35,19 @@ type HistorySummary struct {
 type Store struct {
 	db     *sql.DB
 	dbPath string
+	hooks  []DrawHook
+}
+
+// 开奖入库（inserted）或更正（updated）后的回调，例如兑奖。
+// 由上层注册，避免 store 反向依赖业务包；回调内自行处理错误。
+type DrawHook func(d Draw, status string)
+
+func (s *Store) OnDrawChanged(h DrawHook) { s.hooks = append(s.hooks, h) }
+
+func (s *Store) fireDrawChanged(d Draw, status string) {
+	for _, h := range s.hooks {
+		h(d, status)
+	}
 }
 
 /* --------------------------- Open & Migrate --------------------------- */
@@ -47,17 +58,11 @@ func Open(dataDir string) (*Store, error) {
 	}
 	dbPath := filepath.Join(dataDir, "app.db")
 
-	// mattn 驱动（CGO）。若改用 modernc，请把 "sqlite3" 改为 "sqlite" 并调整 DSN。
-	dsn := dbPath + "?_busy_timeout=5000&_foreign_keys=on"
-
-	db, err := sql.Open("sqlite3", dsn)
+	// 驱动由构建标签选择（见 driver_*.go）；连接级 PRAGMA 写进 DSN，连接池里的每个连接都生效
+	db, err := sql.Open(driverName, dsn(dbPath, connPragmas))
 	if err != nil {
 		return nil, err
 	}
-	if _, err := db.Exec(`PRAGMA journal_mode=WAL; PRAGMA synchronous=NORMAL;`); err != nil {
-		_ = db.Close()
-		return nil, err
-	}
 	if err := migrate(db); err != nil {
 		_ = db.Close()
 		return nil, err
@@ -67,138 +72,6 @@ func Open(dataDir string) (*Store, error) {
 
 func (s *Store) Close() error { return s.db.Close() }
 
-func migrate(db *sql.DB) error {
-	_, err := db.Exec(`
-CREATE TABLE IF NOT EXISTS draws (
-  id         INTEGER PRIMARY KEY AUTOINCREMENT,
-  issue      TEXT UNIQUE,           -- 唯一期号（可为空；为空则不唯一）
-  draw_date  TEXT NOT NULL,         -- YYYY-MM-DD
-  reds       TEXT NOT NULL,         -- JSON 数组，如 "[1,2,3,4,5,6]"
-  blue       INTEGER NOT NULL,
-  source     TEXT,
-  fetched_at TEXT NOT NULL,         -- RFC3339
-  created_at TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%f','now'))
-);
-CREATE INDEX IF NOT EXISTS idx_draws_date ON draws(draw_date DESC, id DESC);
-CREATE UNIQUE INDEX IF NOT EXISTS ux_draws_issue ON draws(issue);
-`)
-	return err
-}
-
-/* -------------------------- Import from Excel ------------------------- */
-
-// 初始化/覆盖导入 Excel（sheet1；第1/2行为表头；第2列日期；第3列为 7 行号码）。
-// replace=false 且已有数据 → ErrAlreadyInitialized
-func (s *Store) ImportExcel(xlsxPath string, replace bool) (int, error) {
-	has, err := s.hasAny()
-	if err != nil {
-		return 0, err
-	}
-	if has && !replace {
-		return 0, ErrAlreadyInitialized
-	}
-
-	tx, err := s.db.Begin()
-	if err != nil {
-		return 0, err
-	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-		} else {
-			_ = tx.Commit()
-		}
-	}()
-
-	if replace {
-		if _, err = tx.Exec(`DELETE FROM draws`); err != nil {
-			return 0, err
-		}
-	}
-
-	f, err := excelize.OpenFile(xlsxPath)
-	if err != nil {
-		return 0, err
-	}
-	defer f.Close()
-
-	sheet := ""
-	for _, name := range f.GetSheetList() {
-		if strings.EqualFold(name, "sheet1") {
-			sheet = name
-			break
-		}
-	}
-	if sheet == "" {
-		l := f.GetSheetList()
-		if len(l) == 0 {
-			return 0, fmt.Errorf("excel 文件无工作表")
-		}
-		sheet = l[0]
-	}
-
-	rows, err := f.GetRows(sheet)
-	if err != nil {
-		return 0, err
-	}
-
-	stmt, err := tx.Prepare(`
-INSERT INTO draws(issue, draw_date, reds, blue, source, fetched_at)
-VALUES(?,?,?,?,?,?)
-`)
-	if err != nil {
-		return 0, err
-	}
-	defer stmt.Close()
-
-	imported := 0
-	nowStr := time.Now().Format(time.RFC3339Nano)
-
-	for i := 2; i < len(rows); i++ { // 跳过前两行表头
-		row := rows[i]
-		if len(row) < 3 {
-			continue
-		}
-		issue := strings.TrimSpace(row[0]) // 期号（可空）
-		drawDate := normalizeDate(strings.TrimSpace(row[1]))
-		codes := strings.TrimSpace(row[2]) // 6 红 + 1 蓝，\n 分行
-		if drawDate == "" || codes == "" {
-			continue
-		}
-
-		parts := strings.Split(codes, "\n")
-		if len(parts) != 7 {
-			continue
-		}
-		reds := make([]int, 0, 6)
-		ok := true
-		for j := 0; j < 6; j++ {
-			var n int
-			if _, e := fmt.Sscanf(strings.TrimSpace(parts[j]), "%d", &n); e != nil || n < 1 || n > 33 {
-				ok = false
-				break
-			}
-			reds = append(reds, n)
-		}
-		if !ok {
-			continue
-		}
-		sort.Ints(reds)
-		var blue int
-		if _, e := fmt.Sscanf(strings.TrimSpace(parts[6]), "%d", &blue); e != nil || blue < 1 || blue > 16 {
-			continue
-		}
-
-		redsJSON, _ := json.Marshal(reds)
-		if _, err := stmt.Exec(nullIfEmpty(issue), drawDate, string(redsJSON), blue, "excel", nowStr); err != nil {
-			return imported, err
-		}
-		imported++
-	}
-
-	return imported, nil
-}
-
 /* -------------------------------- Queries ----------------------------- */
 
 func (s *Store) hasAny() (bool, error) {
@@ -216,114 +89,136 @@ func (s *Store) UpsertDrawByIssue(d Draw) error {
 	if err != nil {
 		return err
 	}
-	redsJSON, _ := json.Marshal(norm.Reds)
 	ts := norm.FetchedAt
 	if ts.IsZero() {
 		ts = time.Now()
 	}
-	tsStr := ts.Format(time.RFC3339Nano)
+	norm.FetchedAt = ts
+	return upsertDraw(s.db, norm)
+}
 
+// *sql.DB 与 *sql.Tx 共用
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// norm 须已 normalizeDraw 且带 FetchedAt
+func upsertDraw(ex execer, norm Draw) error {
+	tsStr := norm.FetchedAt.Format(time.RFC3339Nano)
 	issue := strings.TrimSpace(norm.Issue)
 	if issue == "" {
-		_, err := s.db.Exec(`
-INSERT INTO draws(issue, draw_date, reds, blue, source, fetched_at)
-VALUES(NULL,?,?,?,?,?)
-`, norm.DrawDate, string(redsJSON), norm.Blue, norm.Source, tsStr)
+		_, err := ex.Exec(insertDrawSQL, insertDrawArgs(nil, norm, norm.Source, tsStr)...)
 		return err
 	}
 
-	_, err = s.db.Exec(`
-INSERT INTO draws(issue, draw_date, reds, blue, source, fetched_at)
-VALUES(?,?,?,?,?,?)
+	_, err := ex.Exec(insertDrawSQL+`
 ON CONFLICT(issue) DO UPDATE SET
   draw_date = excluded.draw_date,
   reds      = excluded.reds,
   blue      = excluded.blue,
   source    = excluded.source,
-  fetched_at= excluded.fetched_at
-`, issue, norm.DrawDate, string(redsJSON), norm.Blue, norm.Source, tsStr)
+  fetched_at= excluded.fetched_at,
+  r1 = excluded.r1, r2 = excluded.r2, r3 = excluded.r3,
+  r4 = excluded.r4, r5 = excluded.r5, r6 = excluded.r6,
+  red_key   = excluded.red_key
+`, insertDrawArgs(issue, norm, norm.Source, tsStr)...)
 	return err
 }
 
-// ReconcileIssue：比较后决定 inserted/updated/noop；返回旧值（若存在）
+// 第三方拉取/回补写入的审计身份
+var reconcileAudit = AuditMeta{Actor: "system", Source: "reconcile"}
+
+// ReconcileIssue：比较后决定 inserted/updated/noop；返回旧值（若存在）。
+// 写入与审计同一事务，提交后触发 DrawHook
 func (s *Store) ReconcileIssue(in Draw) (status string, prev *Draw, err error) {
 	norm, err := normalizeDraw(in)
 	if err != nil {
 		return "", nil, err
 	}
-	old, err := s.GetByIssue(norm.Issue)
+	if norm.FetchedAt.IsZero() {
+		norm.FetchedAt = time.Now()
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if err != nil || status == "noop" {
+			_ = tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err == nil {
+			s.fireDrawChanged(norm, status)
+		}
+	}()
+
+	old, err := getByIssue(tx, norm.Issue)
 	if err != nil {
 		return "", nil, err
 	}
-	if old == nil {
-		if err := s.UpsertDrawByIssue(norm); err != nil {
-			return "", nil, err
+	action := "insert"
+	status = "inserted"
+	if old != nil {
+		if sameDraw(*old, norm) {
+			return "noop", old, nil
 		}
-		return "inserted", nil, nil
-	}
-	same := old.DrawDate == norm.DrawDate &&
-		old.Blue == norm.Blue &&
-		len(old.Reds) == 6 &&
-		old.Reds[0] == norm.Reds[0] && old.Reds[1] == norm.Reds[1] &&
-		old.Reds[2] == norm.Reds[2] && old.Reds[3] == norm.Reds[3] &&
-		old.Reds[4] == norm.Reds[4] && old.Reds[5] == norm.Reds[5]
-	if same {
-		return "noop", old, nil
-	}
-	if err := s.UpsertDrawByIssue(norm); err != nil {
+		action, status = "update", "updated"
+	}
+	if err = upsertDraw(tx, norm); err != nil {
+		return "", nil, err
+	}
+	if err = insertAudit(tx, norm.Issue, action, old, &norm, reconcileAudit); err != nil {
 		return "", nil, err
 	}
-	return "updated", old, nil
+	return status, old, nil
 }
 
 func (s *Store) GetByIssue(issue string) (*Draw, error) {
+	return getByIssue(s.db, issue)
+}
+
+// *sql.DB 与 *sql.Tx 共用
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getByIssue(q rowQuerier, issue string) (*Draw, error) {
 	issue = strings.TrimSpace(issue)
 	if issue == "" {
 		return nil, nil
 	}
-	row := s.db.QueryRow(`SELECT issue, draw_date, reds, blue, source, fetched_at
+	row := q.QueryRow(`SELECT `+drawCols+`
 FROM draws WHERE issue=? LIMIT 1`, issue)
-	var out Draw
-	var redsJSON string
-	var fetched string
-	if err := row.Scan(&out.Issue, &out.DrawDate, &redsJSON, &out.Blue, &out.Source, &fetched); err != nil {
+	var r drawRow
+	if err := row.Scan(r.dest()...); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	var reds []int
-	_ = json.Unmarshal([]byte(redsJSON), &reds)
-	out.Reds = reds
-	if t, e := parseTimeFlexible(fetched); e == nil {
-		out.FetchedAt = t
-	}
+	out := r.draw()
 	return &out, nil
 }
 
 func (s *Store) LatestDraw() (*Draw, error) {
-	row := s.db.QueryRow(`SELECT issue, draw_date, reds, blue, source, fetched_at
+	row := s.db.QueryRow(`SELECT ` + drawCols + `
 FROM draws ORDER BY draw_date DESC, id DESC LIMIT 1`)
-	var out Draw
-	var redsJSON string
-	var fetched string
-	if err := row.Scan(&out.Issue, &out.DrawDate, &redsJSON, &out.Blue, &out.Source, &fetched); err != nil {
+	var r drawRow
+	if err := row.Scan(r.dest()...); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	_ = json.Unmarshal([]byte(redsJSON), &out.Reds)
-	if t, e := parseTimeFlexible(fetched); e == nil {
-		out.FetchedAt = t
-	}
+	out := r.draw()
 	return &out, nil
 }
 
 // limit<=0 → 全量（升序）；否则取最近 N 期后再按时间升序返回
 func (s *Store) ListRecentDraws(limit int) ([]Draw, error) {
 	if limit <= 0 {
-		rows, err := s.db.Query(`SELECT issue, draw_date, reds, blue, source, fetched_at
+		rows, err := s.db.Query(`SELECT ` + drawCols + `
 FROM draws ORDER BY draw_date ASC, id ASC`)
 		if err != nil {
 			return nil, err
@@ -331,8 +226,8 @@ FROM draws ORDER BY draw_date ASC, id ASC`)
 		defer rows.Close()
 		return scanRows(rows)
 	}
-	rows, err := s.db.Query(`SELECT issue, draw_date, reds, blue, source, fetched_at FROM (
-  SELECT issue, draw_date, reds, blue, source, fetched_at, id
+	rows, err := s.db.Query(`SELECT `+drawCols+` FROM (
+  SELECT `+drawCols+`, id
   FROM draws ORDER BY draw_date DESC, id DESC LIMIT ?
 ) t ORDER BY draw_date ASC, id ASC`, limit)
 	if err != nil {
@@ -345,19 +240,13 @@ FROM draws ORDER BY draw_date ASC, id ASC`)
 func scanRows(rows *sql.Rows) ([]Draw, error) {
 	var list []Draw
 	for rows.Next() {
-		var d Draw
-		var redsJSON string
-		var fetched string
-		if err := rows.Scan(&d.Issue, &d.DrawDate, &redsJSON, &d.Blue, &d.Source, &fetched); err != nil {
+		var r drawRow
+		if err := rows.Scan(r.dest()...); err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal([]byte(redsJSON), &d.Reds)
-		if t, e := parseTimeFlexible(fetched); e == nil {
-			d.FetchedAt = t
-		}
-		list = append(list, d)
+		list = append(list, r.draw())
 	}
-	return list, nil
+	return list, rows.Err()
 }
 
 /* ------------------- 历史集合 & 频次（供生成器用） ------------------- */
@@ -366,65 +255,143 @@ func (s *Store) HistorySetAndFreq() (map[string]struct{}, [34]int, error) {
 	set := make(map[string]struct{}, 4096)
 	var freq [34]int
 
-	rows, err := s.db.Query(`SELECT reds FROM draws`)
+	rows, err := s.db.Query(`SELECT DISTINCT red_key FROM draws WHERE red_key IS NOT NULL`)
 	if err != nil {
 		return nil, freq, err
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var redsJSON string
-		if err := rows.Scan(&redsJSON); err != nil {
+		var key string
+		if err := rows.Scan(&key); err != nil {
 			return nil, freq, err
 		}
-		var reds []int
-		if e := json.Unmarshal([]byte(redsJSON), &reds); e != nil || len(reds) != 6 {
-			continue
-		}
-		sort.Ints(reds)
-		set[redKey(reds)] = struct{}{}
-		for _, v := range reds {
-			if v >= 1 && v <= 33 {
-				freq[v]++
-			}
-		}
+		set[key] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, freq, err
+	}
+	if freq, err = s.RedFrequency(); err != nil {
+		return nil, freq, err
 	}
 	return set, freq, nil
 }
 
-func (s *Store) HistorySummary() (*HistorySummary, error) {
-	var rowsCnt int
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM draws`).Scan(&rowsCnt); err != nil {
-		return nil, err
-	}
-	// 计算不同红球组合数量（不建派生列，现场计算）
-	rows, err := s.db.Query(`SELECT reds FROM draws`)
+// RedFrequency：全部历史中 1..33 各红球出现次数（下标即号码）
+func (s *Store) RedFrequency() ([34]int, error) {
+	var freq [34]int
+	rows, err := s.db.Query(`SELECT n, COUNT(*) FROM (
+  SELECT r1 AS n FROM draws UNION ALL SELECT r2 FROM draws UNION ALL SELECT r3 FROM draws
+  UNION ALL SELECT r4 FROM draws UNION ALL SELECT r5 FROM draws UNION ALL SELECT r6 FROM draws
+) WHERE n BETWEEN 1 AND 33 GROUP BY n`)
 	if err != nil {
-		return nil, err
+		return freq, err
 	}
 	defer rows.Close()
-	seen := map[string]struct{}{}
 	for rows.Next() {
-		var redsJSON string
-		if err := rows.Scan(&redsJSON); err != nil {
-			return nil, err
-		}
-		var reds []int
-		if e := json.Unmarshal([]byte(redsJSON), &reds); e != nil || len(reds) != 6 {
-			continue
+		var n, c int
+		if err := rows.Scan(&n, &c); err != nil {
+			return freq, err
 		}
-		sort.Ints(reds)
-		seen[redKey(reds)] = struct{}{}
+		freq[n] = c
+	}
+	return freq, rows.Err()
+}
+
+func (s *Store) HistorySummary() (*HistorySummary, error) {
+	var rowsCnt, combos int
+	if err := s.db.QueryRow(`SELECT COUNT(*), COUNT(DISTINCT red_key) FROM draws`).Scan(&rowsCnt, &combos); err != nil {
+		return nil, err
 	}
 	return &HistorySummary{
-		TotalCombos: len(seen),
+		TotalCombos: combos,
 		TotalRows:   rowsCnt,
 		Initialized: rowsCnt > 0,
 		StorePath:   s.dbPath,
 	}, nil
 }
 
+// 同一红球组合开出过多次
+type DuplicateCombo struct {
+	RedKey string   `json:"red_key"`
+	Reds   []int    `json:"reds"`
+	Count  int      `json:"count"`
+	Issues []string `json:"issues"` // 按日期升序
+	Dates  []string `json:"dates"`
+}
+
+// DuplicateCombos：按出现次数倒序；limit<=0 不限
+func (s *Store) DuplicateCombos(limit int) ([]DuplicateCombo, error) {
+	q := `SELECT red_key, COUNT(*) AS c FROM draws WHERE red_key IS NOT NULL
+GROUP BY red_key HAVING c > 1 ORDER BY c DESC, red_key ASC`
+	var args []any
+	if limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	out := []DuplicateCombo{}
+	for rows.Next() {
+		var d DuplicateCombo
+		if err := rows.Scan(&d.RedKey, &d.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		d.Reds, _ = ParseReds(d.RedKey)
+		out = append(out, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		rs, err := s.db.Query(`SELECT COALESCE(issue, ''), draw_date FROM draws WHERE red_key=? ORDER BY draw_date, id`, out[i].RedKey)
+		if err != nil {
+			return nil, err
+		}
+		for rs.Next() {
+			var issue, date string
+			if err := rs.Scan(&issue, &date); err != nil {
+				rs.Close()
+				return nil, err
+			}
+			out[i].Issues = append(out[i].Issues, issue)
+			out[i].Dates = append(out[i].Dates, date)
+		}
+		rs.Close()
+		if err := rs.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
 /* --------------------------------- utils -------------------------------- */
 
+// ValidateDraw：第三方/手工写入前的严格校验：红球 6 个、升序且不重复，蓝球 1..16。
+// 与 normalizeDraw 不同，不会替调用方排序
+func ValidateDraw(d Draw) error {
+	if len(d.Reds) != 6 {
+		return fmt.Errorf("reds must be 6 numbers")
+	}
+	last := 0
+	for _, v := range d.Reds {
+		if v < 1 || v > 33 {
+			return fmt.Errorf("red out of range: %d", v)
+		}
+		if v <= last {
+			return fmt.Errorf("reds must be strictly increasing")
+		}
+		last = v
+	}
+	if d.Blue < 1 || d.Blue > 16 {
+		return fmt.Errorf("blue out of range: %d", d.Blue)
+	}
+	return nil
+}
+
 func normalizeDraw(d Draw) (Draw, error) {
 	if len(d.DrawDate) >= 10 {
 		d.DrawDate = strings.TrimSpace(d.DrawDate[:10])
@@ -450,7 +417,8 @@ func normalizeDraw(d Draw) (Draw, error) {
 	return d, nil
 }
 
-func normalizeDate(s string) string {
+// NormalizeDate：各种日期写法（含括号星期、斜杠、时间部分）→ YYYY-MM-DD
+func NormalizeDate(s string) string {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return ""
@@ -472,6 +440,48 @@ func normalizeDate(s string) string {
 	return s
 }
 
+// ParseLineInts：解析 "01,05,12,18,25,31+09" 形式（红球也可用空格分隔）
+func ParseLineInts(s string) ([]int, int, error) {
+	s = strings.TrimSpace(s)
+	left, right, ok := strings.Cut(s, "+")
+	if !ok {
+		return nil, 0, fmt.Errorf("missing '+' part")
+	}
+	red, err := ParseReds(left)
+	if err != nil {
+		return nil, 0, err
+	}
+	// 解析蓝球
+	blue, err := strconv.Atoi(strings.TrimSpace(right))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid plus number: %v", err)
+	}
+	if blue < 1 || blue > 16 {
+		return nil, 0, fmt.Errorf("blue out of range: %d", blue)
+	}
+	return red, blue, nil
+}
+
+// ParseReds：逗号/空白分隔的红球，返回升序
+func ParseReds(s string) ([]int, error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == ' ' || r == '\t'
+	})
+	red := make([]int, 0, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number at index %d: %v", i, err)
+		}
+		if n < 1 || n > 33 {
+			return nil, fmt.Errorf("red out of range: %d", n)
+		}
+		red = append(red, n)
+	}
+	sort.Ints(red)
+	return red, nil
+}
+
 func parseTimeFlexible(s string) (time.Time, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -487,10 +497,88 @@ func parseTimeFlexible(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unsupported time: %s", s)
 }
 
+// 日期与号码一致（不比较来源与抓取时间）
+func sameDraw(a, b Draw) bool {
+	if a.DrawDate != b.DrawDate || a.Blue != b.Blue || len(a.Reds) != 6 || len(b.Reds) != 6 {
+		return false
+	}
+	for i := range a.Reds {
+		if a.Reds[i] != b.Reds[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func redKey(reds []int) string {
 	return fmt.Sprintf("%02d,%02d,%02d,%02d,%02d,%02d", reds[0], reds[1], reds[2], reds[3], reds[4], reds[5])
 }
 
+// draws 的写入统一走这两条语句：r1..r6 / red_key 由 reds 派生，不能单独漏写
+const (
+	insertDrawSQL = `INSERT INTO draws(issue, draw_date, reds, blue, source, fetched_at, r1, r2, r3, r4, r5, r6, red_key)
+VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`
+	updateDrawSQL = `UPDATE draws SET draw_date=?, reds=?, blue=?, source=?, fetched_at=?,
+  r1=?, r2=?, r3=?, r4=?, r5=?, r6=?, red_key=? WHERE issue=?`
+)
+
+// d.Reds 须已升序；issue 为 nil 时写 NULL
+func insertDrawArgs(issue any, d Draw, source, ts string) []any {
+	redsJSON, _ := json.Marshal(d.Reds)
+	args := []any{issue, d.DrawDate, string(redsJSON), d.Blue, source, ts}
+	return append(args, redArgs(d.Reds)...)
+}
+
+func updateDrawArgs(d Draw, source, ts string) []any {
+	redsJSON, _ := json.Marshal(d.Reds)
+	args := []any{d.DrawDate, string(redsJSON), d.Blue, source, ts}
+	return append(append(args, redArgs(d.Reds)...), d.Issue)
+}
+
+// r1..r6, red_key
+func redArgs(reds []int) []any {
+	out := make([]any, 0, 7)
+	for _, v := range reds {
+		out = append(out, v)
+	}
+	return append(out, redKey(reds))
+}
+
+// 读取统一走 r1..r6：与频次/查询/重号统计同一口径，reds（JSON）只为兼容旧版本随写入保留
+const drawCols = `issue, draw_date, r1, r2, r3, r4, r5, r6, blue, source, fetched_at`
+
+// drawRow：按 drawCols 的顺序扫描一行 draws
+type drawRow struct {
+	issue, source, fetched sql.NullString
+	drawDate               string
+	reds                   [6]sql.NullInt64
+	blue                   int
+}
+
+func (r *drawRow) dest() []any {
+	return []any{&r.issue, &r.drawDate,
+		&r.reds[0], &r.reds[1], &r.reds[2], &r.reds[3], &r.reds[4], &r.reds[5],
+		&r.blue, &r.source, &r.fetched}
+}
+
+// 迁移时无法解析的旧行 r1..r6 为 NULL，此时 Reds 为空
+func (r *drawRow) draw() Draw {
+	d := Draw{Issue: r.issue.String, DrawDate: r.drawDate, Blue: r.blue, Source: r.source.String}
+	reds := make([]int, 0, 6)
+	for _, v := range r.reds {
+		if !v.Valid {
+			reds = nil
+			break
+		}
+		reds = append(reds, int(v.Int64))
+	}
+	d.Reds = reds
+	if t, e := parseTimeFlexible(r.fetched.String); e == nil {
+		d.FetchedAt = t
+	}
+	return d
+}
+
 func nullIfEmpty(s string) any {
 	if strings.TrimSpace(s) == "" {
 		return nil
@@ -4,10 +4,21 @@ go 1.24
 
 require github.com/xuri/excelize/v2 v2.9.1
 
-require github.com/kr/text v0.2.0 // indirect
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
+	modernc.org/libc v1.66.3 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
+)
 
 require (
 	github.com/mattn/go-sqlite3 v1.14.32
+	modernc.org/sqlite v1.38.2
 	resty.dev/v3 v3.0.0-beta.3
 )
 
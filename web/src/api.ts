@@ -5,8 +5,8 @@ export interface BandTpl { vals: [number, number, number] }
 export interface StartBucket { From: number; To: number; Count: number }
 export interface BandRangeGo { LowLo: number; LowHi: number; MidLo: number; MidHi: number; HighLo: number; HighHi: number }
 export interface GenConfig {
-  Mode: number
-  Animal: number
+  Mode: number | string      // 'random' | 'zodiac' | 'birthday' | 'mixed'（后端输出名称）
+  Animal: number | string    // 'Rat'..'Pig'
   Birthday: string
 
   GenerateCount: number
@@ -16,7 +16,7 @@ export interface GenConfig {
   BlueFilter: number[]
   FixedRed: number[]
 
-  FMode: number
+  FMode: number | string     // 'always' | 'rotate'
   FixedPerTicket: number
 
   MaxOverlapRed: number